@@ -16,7 +16,8 @@ type Range struct {
 	End   Position `json:"end"`
 }
 
-type Choice2[OPT1 any, OPT2 any] struct {
-	Opt1 *OPT1
-	Opt2 *OPT2
+type Command struct {
+	Title     string         `json:"title"`
+	Command   string         `json:"command"`
+	Arguments *[]interface{} `json:"arguments,omitempty"`
 }
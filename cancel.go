@@ -0,0 +1,70 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+// Supporting types
+const (
+	CancelRequestMethod string = "$/cancelRequest"
+)
+
+type CancelParams struct {
+	Id interface{} `json:"id"`
+}
+
+func addCancelMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(CancelRequestMethod, ClientToServer, processCancelRequest[Ctxt]))
+}
+
+func processCancelRequest[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params CancelParams) error {
+	key, err := jsonKey(params.Id)
+	if err != nil {
+		return err
+	}
+
+	srv.lock.Lock()
+	cancel := srv.requests[key]
+	srv.lock.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	return nil
+}
+
+/**
+ *	trackRequest registers the cancel function of an in-flight request and returns the function removing it once answered.
+ */
+func (s *Server[Ctxt]) trackRequest(id jsonrpc.RequestId, cancel context.CancelFunc) func() {
+	key, err := jsonKey(id)
+	if err != nil {
+		return func() {}
+	}
+
+	s.lock.Lock()
+	s.requests[key] = cancel
+	s.lock.Unlock()
+
+	return func() {
+		s.lock.Lock()
+		delete(s.requests, key)
+		s.lock.Unlock()
+	}
+}
+
+/**
+ *	jsonKey returns the JSON representation of an id or token so that values decoded from notifications match the original ones.
+ */
+func jsonKey(id interface{}) (string, error) {
+	data, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
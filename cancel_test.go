@@ -0,0 +1,70 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+func TestCancelRequest(t *testing.T) {
+	cancelled := jsonrpc.NewError(ErrorCode_RequestCancelled, "Request was cancelled.", nil)
+
+	tests := []struct {
+		id       jsonrpc.RequestId
+		cancelId string
+		want     error
+	}{
+		{7, `7`, cancelled},
+		{"req-7", `"req-7"`, cancelled},
+		// Cancelling another request leaves the handler running until it completes on its own.
+		{7, `"7"`, nil},
+		{7, `8`, nil},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		srv.setState(ServerState_Initialized)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		srv.Methods().Add(NewRequest("test/wait", ClientToServer, func(ctx context.Context, srv *Server[struct{}], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params Void) (*Void, error) {
+			close(started)
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return &Void{}, nil
+			}
+		}))
+
+		done := make(chan error, 1)
+
+		go func() {
+			_, err := srv.processRequest(context.Background(), nil, nil, test.id, "test/wait", json.RawMessage(`{}`))
+			done <- err
+		}()
+
+		<-started
+
+		if err := srv.processNotification(context.Background(), nil, nil, CancelRequestMethod, json.RawMessage(`{"id":`+test.cancelId+`}`)); err != nil {
+			t.Fatalf("$/cancelRequest failed: %v", err)
+		}
+
+		if test.want == nil {
+			close(release)
+		}
+
+		if err := <-done; !reflect.DeepEqual(err, test.want) {
+			t.Errorf("request %v cancelled with %s failed with %v, want %v", test.id, test.cancelId, err, test.want)
+		}
+
+		if len(srv.requests) != 0 {
+			t.Errorf("request %v is still tracked after completion", test.id)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package lsp
+
+/**
+ *	CapabilitiesHandler completes the ServerCapabilities advertised for the methods added alongside it.
+ */
+type CapabilitiesHandler func(caps *ServerCapabilities)
+
+/**
+ *	AddCapabilities registers a handler called when computing the capabilities of the set, after the built-in ones.
+ */
+func (s *MethodSet[Ctxt]) AddCapabilities(handler CapabilitiesHandler) {
+	s.caps = append(s.caps, handler)
+}
+
+/**
+ *	Capabilities returns the given base ServerCapabilities completed with the providers implied by the methods in the set.
+ *	Values explicitly set in base are left untouched.
+ */
+func (s *MethodSet[Ctxt]) Capabilities(base ServerCapabilities) ServerCapabilities {
+	caps := base
+
+	s.fillTextDocumentSyncCapabilities(&caps)
+	s.fillWorkspaceCapabilities(&caps)
+
+	for _, handler := range s.caps {
+		handler(&caps)
+	}
+
+	return caps
+}
+
+func (s *MethodSet[Ctxt]) Has(method string) bool {
+	return s.Get(method) != nil
+}
+
+func (s *MethodSet[Ctxt]) fillTextDocumentSyncCapabilities(caps *ServerCapabilities) {
+	var sync TextDocumentSyncOptions
+
+	if caps.TextDocumentSync != nil {
+		sync = *caps.TextDocumentSync
+	}
+
+	if s.Has(Method_DidOpenTextDocument) || s.Has(Method_DidCloseTextDocument) {
+		sync.OpenClose = true
+	}
+
+	if s.Has(Method_DidChangeTextDocument) && sync.Change == TextDocumentSyncKind_None {
+		sync.Change = TextDocumentSyncKind_Full
+	}
+
+	if s.Has(Method_WillSaveTextDocument) {
+		sync.WillSave = true
+	}
+
+	if s.Has(Method_WillSaveWaitUntil) {
+		sync.WillSaveWaitUntil = true
+	}
+
+	if s.Has(Method_DidSaveTextDocument) && sync.Save == nil {
+		sync.Save = &SaveOptions{}
+	}
+
+	if sync != (TextDocumentSyncOptions{}) {
+		caps.TextDocumentSync = &sync
+	}
+}
+
+func (s *MethodSet[Ctxt]) fillWorkspaceCapabilities(caps *ServerCapabilities) {
+	var ws WorkspaceOptions
+
+	if caps.Workspace != nil {
+		ws = *caps.Workspace
+	}
+
+	if s.Has(DidChangeWorkspaceFoldersMethod) && ws.WorkspaceFolders == nil {
+		notify := true
+
+		ws.WorkspaceFolders = &WorkspaceFoldersServerCapabilities{
+			Supported:           true,
+			ChangeNotifications: &Choice2[string, bool]{Opt2: &notify},
+		}
+	}
+
+	if ws != (WorkspaceOptions{}) {
+		caps.Workspace = &ws
+	}
+}
@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/**
+ *	ChoiceN structs model the union types of the protocol: exactly one option is set.
+ *	They are encoded as the set option; decoding tries each option in turn, first rejecting unknown fields so that
+ *	structurally close options are told apart, then accepting them.
+ *	Options sharing the same fields implement choiceMatcher to be selected by the value of a discriminating field.
+ */
+type Choice2[OPT1 any, OPT2 any] struct {
+	Opt1 *OPT1
+	Opt2 *OPT2
+}
+
+type Choice3[OPT1 any, OPT2 any, OPT3 any] struct {
+	Opt1 *OPT1
+	Opt2 *OPT2
+	Opt3 *OPT3
+}
+
+type Choice4[OPT1 any, OPT2 any, OPT3 any, OPT4 any] struct {
+	Opt1 *OPT1
+	Opt2 *OPT2
+	Opt3 *OPT3
+	Opt4 *OPT4
+}
+
+func (c Choice2[OPT1, OPT2]) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Opt1 != nil:
+		return json.Marshal(c.Opt1)
+	case c.Opt2 != nil:
+		return json.Marshal(c.Opt2)
+	}
+
+	return []byte("null"), nil
+}
+
+func (c *Choice2[OPT1, OPT2]) UnmarshalJSON(data []byte) error {
+	*c = Choice2[OPT1, OPT2]{}
+
+	return unmarshalChoice(
+		data,
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt1, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt2, strict) },
+	)
+}
+
+func (c Choice3[OPT1, OPT2, OPT3]) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Opt1 != nil:
+		return json.Marshal(c.Opt1)
+	case c.Opt2 != nil:
+		return json.Marshal(c.Opt2)
+	case c.Opt3 != nil:
+		return json.Marshal(c.Opt3)
+	}
+
+	return []byte("null"), nil
+}
+
+func (c *Choice3[OPT1, OPT2, OPT3]) UnmarshalJSON(data []byte) error {
+	*c = Choice3[OPT1, OPT2, OPT3]{}
+
+	return unmarshalChoice(
+		data,
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt1, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt2, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt3, strict) },
+	)
+}
+
+func (c Choice4[OPT1, OPT2, OPT3, OPT4]) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Opt1 != nil:
+		return json.Marshal(c.Opt1)
+	case c.Opt2 != nil:
+		return json.Marshal(c.Opt2)
+	case c.Opt3 != nil:
+		return json.Marshal(c.Opt3)
+	case c.Opt4 != nil:
+		return json.Marshal(c.Opt4)
+	}
+
+	return []byte("null"), nil
+}
+
+func (c *Choice4[OPT1, OPT2, OPT3, OPT4]) UnmarshalJSON(data []byte) error {
+	*c = Choice4[OPT1, OPT2, OPT3, OPT4]{}
+
+	return unmarshalChoice(
+		data,
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt1, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt2, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt3, strict) },
+		func(strict bool) bool { return tryUnmarshal(data, &c.Opt4, strict) },
+	)
+}
+
+/**
+ *	choiceMatcher interface is implemented by union options whose fields alone do not identify them, such as the
+ *	resource operations told apart by their kind.
+ */
+type choiceMatcher interface {
+	matchesChoice() bool
+}
+
+func unmarshalChoice(data []byte, options ...func(strict bool) bool) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	for _, strict := range []bool{true, false} {
+		for _, option := range options {
+			if option(strict) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("value does not match any option of the union: %s", data)
+}
+
+func tryUnmarshal[T any](data []byte, dst **T, strict bool) bool {
+	var val T
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&val); err != nil {
+		return false
+	}
+
+	if m, ok := any(&val).(choiceMatcher); ok && !m.matchesChoice() {
+		return false
+	}
+
+	*dst = &val
+	return true
+}
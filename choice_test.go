@@ -0,0 +1,98 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChoiceUnmarshal(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{`{"line":1,"character":2}`, 1},
+		{`{"start":{"line":1,"character":2},"end":{"line":1,"character":4}}`, 2},
+		// Unknown fields are accepted once no option matches strictly.
+		{`{"line":1,"character":2,"extra":1}`, 1},
+		{`[1,2]`, 0},
+		{`null`, 0},
+	}
+
+	for _, test := range tests {
+		var choice Choice2[Position, Range]
+
+		err := json.Unmarshal([]byte(test.data), &choice)
+
+		got := 0
+		switch {
+		case choice.Opt1 != nil:
+			got = 1
+		case choice.Opt2 != nil:
+			got = 2
+		}
+
+		if got != test.want {
+			t.Errorf("%s decoded as option %d (%v), want %d", test.data, got, err, test.want)
+		}
+	}
+}
+
+func TestChoiceUnmarshalScalars(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{`"text"`, 1},
+		{`true`, 2},
+		{`{}`, 0},
+	}
+
+	for _, test := range tests {
+		var choice Choice2[string, bool]
+
+		err := json.Unmarshal([]byte(test.data), &choice)
+
+		got := 0
+		switch {
+		case choice.Opt1 != nil:
+			got = 1
+		case choice.Opt2 != nil:
+			got = 2
+		}
+
+		if got != test.want || (got == 0) != (err != nil) {
+			t.Errorf("%s decoded as option %d (%v), want %d", test.data, got, err, test.want)
+		}
+	}
+}
+
+func TestChoiceMarshal(t *testing.T) {
+	label := "x"
+	offsets := [2]uint{1, 3}
+
+	tests := []struct {
+		value ParameterLabel
+		want  string
+	}{
+		{ParameterLabel{Opt1: &label}, `"x"`},
+		{ParameterLabel{Opt2: &offsets}, `[1,3]`},
+		{ParameterLabel{}, `null`},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.value)
+		if err != nil || string(data) != test.want {
+			t.Errorf("Marshal = %s, %v, want %s", data, err, test.want)
+		}
+
+		if test.value.Opt1 == nil && test.value.Opt2 == nil {
+			continue
+		}
+
+		var back ParameterLabel
+
+		if err := json.Unmarshal(data, &back); err != nil || (back.Opt1 != nil) != (test.value.Opt1 != nil) {
+			t.Errorf("Unmarshal(%s) = %+v, %v", data, back, err)
+		}
+	}
+}
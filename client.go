@@ -0,0 +1,134 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+/**
+ *	ClientCapabilityCheck reports whether the client capabilities allow a server to client method to be sent.
+ */
+type ClientCapabilityCheck func(caps ClientCapabilities) bool
+
+/**
+ *	ClientCapabilityDefinition may be implemented by a MethodDefinition sent to the client to restrict it to capable clients.
+ */
+type ClientCapabilityDefinition interface {
+	ClientSupports(caps ClientCapabilities) bool
+}
+
+type serverToClientRequest[Ctxt any] struct {
+	RequestDefinition[Ctxt]
+
+	supported ClientCapabilityCheck
+}
+
+type serverToClientNotification[Ctxt any] struct {
+	NotificationDefinition[Ctxt]
+
+	supported ClientCapabilityCheck
+}
+
+/**
+ *	NewServerToClientRequest describes a request sent by the server and handled by the client.
+ *	supported may be nil when every client handles the request.
+ */
+func NewServerToClientRequest[Ctxt any, PA any, RE any](method string, supported ClientCapabilityCheck) RequestDefinition[Ctxt] {
+	return serverToClientRequest[Ctxt]{
+		RequestDefinition: NewRawRequest[Ctxt](method, ServerToClient, typeOf[PA](), typeOf[RE](), nil),
+		supported:         supported,
+	}
+}
+
+/**
+ *	NewServerToClientNotification describes a notification sent by the server and handled by the client.
+ *	supported may be nil when every client handles the notification.
+ */
+func NewServerToClientNotification[Ctxt any, PA any](method string, supported ClientCapabilityCheck) NotificationDefinition[Ctxt] {
+	return serverToClientNotification[Ctxt]{
+		NotificationDefinition: NewRawNotification[Ctxt](method, ServerToClient, typeOf[PA](), nil),
+		supported:              supported,
+	}
+}
+
+func (r serverToClientRequest[Ctxt]) ClientSupports(caps ClientCapabilities) bool {
+	return r.supported == nil || r.supported(caps)
+}
+
+func (n serverToClientNotification[Ctxt]) ClientSupports(caps ClientCapabilities) bool {
+	return n.supported == nil || n.supported(caps)
+}
+
+func addClientMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewServerToClientNotification[Ctxt, ProgressParams](ProgressMethod, nil))
+}
+
+/**
+ *	Call sends a request to the client and waits for its result or for ctx to be done.
+ *	The method must be registered in the server MethodSet as a server to client request with matching types.
+ */
+func Call[Ctxt any, PA any, RE any](ctx context.Context, srv *Server[Ctxt], method string, params PA) (*RE, error) {
+	def, ok := srv.methods.Get(method).(RequestDefinition[Ctxt])
+	if !ok || def.Direction()&ServerToClient == 0 {
+		return nil, fmt.Errorf("method '%s' is not a server to client request", method)
+	}
+
+	if def.ParamsType() != typeOf[PA]() || def.ResultType() != typeOf[RE]() {
+		return nil, fmt.Errorf("method '%s' is not defined with types %v and %v", method, typeOf[PA](), typeOf[RE]())
+	}
+
+	if err := srv.checkClientMethod(def); err != nil {
+		return nil, err
+	}
+
+	raw, err := srv.client.Call(ctx, nil, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var res RE
+
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err = json.Unmarshal(raw, &res); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+/**
+ *	Notify sends a notification to the client.
+ *	The method must be registered in the server MethodSet as a server to client notification.
+ */
+func (s *Server[Ctxt]) Notify(method string, params interface{}) error {
+	def, ok := s.methods.Get(method).(NotificationDefinition[Ctxt])
+	if !ok || def.Direction()&ServerToClient == 0 {
+		return fmt.Errorf("method '%s' is not a server to client notification", method)
+	}
+
+	if params != nil && reflect.TypeOf(params) != def.ParamsType() {
+		return fmt.Errorf("method '%s' is not defined with type %v", method, reflect.TypeOf(params))
+	}
+
+	if err := s.checkClientMethod(def); err != nil {
+		return err
+	}
+
+	return s.client.Notify(nil, method, params)
+}
+
+func (s *Server[Ctxt]) checkClientMethod(def MethodDefinition[Ctxt]) error {
+	if s.client == nil {
+		return ErrNotServing
+	}
+
+	if check, ok := def.(ClientCapabilityDefinition); ok && !check.ClientSupports(s.ClientInfo().Capabilites) {
+		return fmt.Errorf("%w: %s", ErrClientUnsupported, def.Method())
+	}
+
+	return nil
+}
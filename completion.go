@@ -0,0 +1,307 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	CompletionProvider interface answers textDocument/completion requests.
+ *	Items may be streamed through partial, which is nil when the client did not ask for partial results; the returned
+ *	list still carries isIncomplete and itemDefaults in that case.
+ *	Snippet items are converted to plain text automatically for clients without snippet support.
+ */
+type CompletionProvider interface {
+	CompletionOptions() CompletionOptions
+	Completion(ctx context.Context, params CompletionParams, partial *PartialResult[[]CompletionItem]) (*CompletionList, error)
+}
+
+/**
+ *	CompletionResolveProvider may be implemented by a CompletionProvider to fill in the details of an item lazily
+ *	through completionItem/resolve.
+ */
+type CompletionResolveProvider interface {
+	ResolveCompletionItem(ctx context.Context, item CompletionItem) (*CompletionItem, error)
+}
+
+/**
+ *	AddCompletionMethods adds the completion methods dispatching to provider to the set and advertises its options.
+ *	completionItem/resolve is only added when provider implements CompletionResolveProvider.
+ */
+func AddCompletionMethods[Ctxt any](set *MethodSet[Ctxt], provider CompletionProvider) {
+	opts := provider.CompletionOptions()
+
+	set.Add(NewRequestWithPartial(Method_Completion, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params CompletionParams, partial *PartialResult[[]CompletionItem]) (*CompletionList, error) {
+		var format *InsertTextFormat
+		snippets := supportsSnippets(srv.ClientInfo().Capabilites)
+
+		if partial != nil && !snippets {
+			// Streamed items may rely on the insertTextFormat default of the list, they are held until it is known.
+			partial.hold = true
+			partial.adapt = func(items []CompletionItem) []CompletionItem {
+				return downgradeCompletionItems(items, format)
+			}
+		}
+
+		list, err := provider.Completion(ctx, params, partial)
+		if err != nil {
+			return nil, err
+		}
+
+		if list != nil && !snippets {
+			if list.ItemDefaults != nil && list.ItemDefaults.InsertTextFormat != nil {
+				def := *list.ItemDefaults.InsertTextFormat
+				format = &def
+			}
+
+			list.DowngradeSnippets()
+		}
+
+		return list, nil
+	}))
+
+	if resolver, ok := provider.(CompletionResolveProvider); ok {
+		resolve := true
+		opts.ResolveProvider = &resolve
+
+		set.Add(NewRequest(Method_CompletionResolve, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params CompletionItem) (*CompletionItem, error) {
+			item, err := resolver.ResolveCompletionItem(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			if item != nil && !supportsSnippets(srv.ClientInfo().Capabilites) {
+				item.DowngradeSnippet()
+			}
+
+			return item, nil
+		}))
+	}
+
+	set.AddCapabilities(func(caps *ServerCapabilities) {
+		if caps.CompletionProvider == nil {
+			caps.CompletionProvider = &opts
+		}
+	})
+}
+
+/**
+ *	DecodeData decodes the data attached to the item, typically when resolving it, into v.
+ */
+func (i CompletionItem) DecodeData(v interface{}) error {
+	raw, err := json.Marshal(i.Data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+func (l CompletionList) PartialResult() []CompletionItem {
+	return l.Items
+}
+
+/**
+ *	PartialResultRemainder keeps isIncomplete and itemDefaults in the final response once the items were streamed.
+ */
+func (l CompletionList) PartialResultRemainder() CompletionList {
+	l.Items = nil
+	return l
+}
+
+func (l CompletionList) MarshalJSON() ([]byte, error) {
+	type list CompletionList
+
+	// items is mandatory, an empty list must not be encoded as null.
+	if l.Items == nil {
+		l.Items = []CompletionItem{}
+	}
+
+	return json.Marshal(list(l))
+}
+
+// Supporting types
+const (
+	Method_Completion        string = "textDocument/completion"
+	Method_CompletionResolve string = "completionItem/resolve"
+)
+
+type CompletionClientCapabilities struct {
+	DynamicRegistration bool                                            `json:"dynamicRegistration,omitempty"`
+	CompletionItem      *CompletionClientCapabilitiesCompletionItem     `json:"completionItem,omitempty"`
+	CompletionItemKind  *CompletionClientCapabilitiesCompletionItemKind `json:"completionItemKind,omitempty"`
+	ContextSupport      bool                                            `json:"contextSupport,omitempty"`
+	InsertTextMode      *InsertTextMode                                 `json:"insertTextMode,omitempty"`
+	CompletionList      *CompletionClientCapabilitiesCompletionList     `json:"completionList,omitempty"`
+}
+
+type CompletionClientCapabilitiesCompletionItem struct {
+	SnippetSupport          bool                                                             `json:"snippetSupport,omitempty"`
+	CommitCharactersSupport bool                                                             `json:"commitCharactersSupport,omitempty"`
+	DocumentationFormat     *[]MarkupKind                                                    `json:"documentationFormat,omitempty"`
+	DeprecatedSupport       bool                                                             `json:"deprecatedSupport,omitempty"`
+	PreselectSupport        bool                                                             `json:"preselectSupport,omitempty"`
+	TagSupport              *CompletionClientCapabilitiesCompletionItemTagSupport            `json:"tagSupport,omitempty"`
+	InsertReplaceSupport    bool                                                             `json:"insertReplaceSupport,omitempty"`
+	ResolveSupport          *CompletionClientCapabilitiesCompletionItemResolveSupport        `json:"resolveSupport,omitempty"`
+	InsertTextModeSupport   *CompletionClientCapabilitiesCompletionItemInsertTextModeSupport `json:"insertTextModeSupport,omitempty"`
+	LabelDetailsSupport     bool                                                             `json:"labelDetailsSupport,omitempty"`
+}
+
+type CompletionClientCapabilitiesCompletionItemTagSupport struct {
+	ValueSet []CompletionItemTag `json:"valueSet"`
+}
+
+type CompletionClientCapabilitiesCompletionItemResolveSupport struct {
+	Properties []string `json:"properties"`
+}
+
+type CompletionClientCapabilitiesCompletionItemInsertTextModeSupport struct {
+	ValueSet []InsertTextMode `json:"valueSet"`
+}
+
+type CompletionClientCapabilitiesCompletionItemKind struct {
+	ValueSet *[]CompletionItemKind `json:"valueSet,omitempty"`
+}
+
+type CompletionClientCapabilitiesCompletionList struct {
+	ItemDefaults *[]string `json:"itemDefaults,omitempty"`
+}
+
+type CompletionOptions struct {
+	WorkDoneProgressOptions
+
+	TriggerCharacters   *[]string                        `json:"triggerCharacters,omitempty"`
+	AllCommitCharacters *[]string                        `json:"allCommitCharacters,omitempty"`
+	ResolveProvider     *bool                            `json:"resolveProvider,omitempty"`
+	CompletionItem      *CompletionOptionsCompletionItem `json:"completionItem,omitempty"`
+}
+
+type CompletionOptionsCompletionItem struct {
+	LabelDetailsSupport *bool `json:"labelDetailsSupport,omitempty"`
+}
+
+type CompletionParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+
+	Context *CompletionContext `json:"context,omitempty"`
+}
+
+type CompletionContext struct {
+	TriggerKind      CompletionTriggerKind `json:"triggerKind"`
+	TriggerCharacter *string               `json:"triggerCharacter,omitempty"`
+}
+
+type CompletionTriggerKind int
+
+const (
+	CompletionTriggerKind_Invoked                         CompletionTriggerKind = 1
+	CompletionTriggerKind_TriggerCharacter                CompletionTriggerKind = 2
+	CompletionTriggerKind_TriggerForIncompleteCompletions CompletionTriggerKind = 3
+)
+
+type CompletionList struct {
+	IsIncomplete bool                    `json:"isIncomplete"`
+	ItemDefaults *CompletionItemDefaults `json:"itemDefaults,omitempty"`
+	Items        []CompletionItem        `json:"items"`
+}
+
+type CompletionItemDefaults struct {
+	CommitCharacters *[]string                           `json:"commitCharacters,omitempty"`
+	EditRange        *Choice2[Range, InsertReplaceRange] `json:"editRange,omitempty"`
+	InsertTextFormat *InsertTextFormat                   `json:"insertTextFormat,omitempty"`
+	InsertTextMode   *InsertTextMode                     `json:"insertTextMode,omitempty"`
+	Data             interface{}                         `json:"data,omitempty"`
+}
+
+type InsertReplaceRange struct {
+	Insert  Range `json:"insert"`
+	Replace Range `json:"replace"`
+}
+
+type CompletionItem struct {
+	Label               string                                `json:"label"`
+	LabelDetails        *CompletionItemLabelDetails           `json:"labelDetails,omitempty"`
+	Kind                *CompletionItemKind                   `json:"kind,omitempty"`
+	Tags                *[]CompletionItemTag                  `json:"tags,omitempty"`
+	Detail              *string                               `json:"detail,omitempty"`
+	Documentation       *Choice2[string, MarkupContent]       `json:"documentation,omitempty"`
+	Deprecated          *bool                                 `json:"deprecated,omitempty"`
+	Preselect           *bool                                 `json:"preselect,omitempty"`
+	SortText            *string                               `json:"sortText,omitempty"`
+	FilterText          *string                               `json:"filterText,omitempty"`
+	InsertText          *string                               `json:"insertText,omitempty"`
+	InsertTextFormat    *InsertTextFormat                     `json:"insertTextFormat,omitempty"`
+	InsertTextMode      *InsertTextMode                       `json:"insertTextMode,omitempty"`
+	TextEdit            *Choice2[TextEdit, InsertReplaceEdit] `json:"textEdit,omitempty"`
+	TextEditText        *string                               `json:"textEditText,omitempty"`
+	AdditionalTextEdits *[]TextEdit                           `json:"additionalTextEdits,omitempty"`
+	CommitCharacters    *[]string                             `json:"commitCharacters,omitempty"`
+	Command             *Command                              `json:"command,omitempty"`
+	Data                interface{}                           `json:"data,omitempty"`
+}
+
+type CompletionItemLabelDetails struct {
+	Detail      *string `json:"detail,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type InsertReplaceEdit struct {
+	NewText string `json:"newText"`
+	Insert  Range  `json:"insert"`
+	Replace Range  `json:"replace"`
+}
+
+type InsertTextFormat int
+
+const (
+	InsertTextFormat_PlainText InsertTextFormat = 1
+	InsertTextFormat_Snippet   InsertTextFormat = 2
+)
+
+type InsertTextMode int
+
+const (
+	InsertTextMode_AsIs              InsertTextMode = 1
+	InsertTextMode_AdjustIndentation InsertTextMode = 2
+)
+
+type CompletionItemTag int
+
+const (
+	CompletionItemTag_Deprecated CompletionItemTag = 1
+)
+
+type CompletionItemKind int
+
+const (
+	CompletionItemKind_Text          CompletionItemKind = 1
+	CompletionItemKind_Method        CompletionItemKind = 2
+	CompletionItemKind_Function      CompletionItemKind = 3
+	CompletionItemKind_Constructor   CompletionItemKind = 4
+	CompletionItemKind_Field         CompletionItemKind = 5
+	CompletionItemKind_Variable      CompletionItemKind = 6
+	CompletionItemKind_Class         CompletionItemKind = 7
+	CompletionItemKind_Interface     CompletionItemKind = 8
+	CompletionItemKind_Module        CompletionItemKind = 9
+	CompletionItemKind_Property      CompletionItemKind = 10
+	CompletionItemKind_Unit          CompletionItemKind = 11
+	CompletionItemKind_Value         CompletionItemKind = 12
+	CompletionItemKind_Enum          CompletionItemKind = 13
+	CompletionItemKind_Keyword       CompletionItemKind = 14
+	CompletionItemKind_Snippet       CompletionItemKind = 15
+	CompletionItemKind_Color         CompletionItemKind = 16
+	CompletionItemKind_File          CompletionItemKind = 17
+	CompletionItemKind_Reference     CompletionItemKind = 18
+	CompletionItemKind_Folder        CompletionItemKind = 19
+	CompletionItemKind_EnumMember    CompletionItemKind = 20
+	CompletionItemKind_Constant      CompletionItemKind = 21
+	CompletionItemKind_Struct        CompletionItemKind = 22
+	CompletionItemKind_Event         CompletionItemKind = 23
+	CompletionItemKind_Operator      CompletionItemKind = 24
+	CompletionItemKind_TypeParameter CompletionItemKind = 25
+)
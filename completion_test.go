@@ -0,0 +1,159 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+const snippetClientCapabilities = `{"textDocument":{"completion":{"completionItem":{"snippetSupport":true}}}}`
+
+type testCompletionProvider struct {
+	streamed []CompletionItem
+	list     CompletionList
+}
+
+func (p testCompletionProvider) CompletionOptions() CompletionOptions {
+	return CompletionOptions{}
+}
+
+func (p testCompletionProvider) Completion(ctx context.Context, params CompletionParams, partial *PartialResult[[]CompletionItem]) (*CompletionList, error) {
+	if partial != nil && p.streamed != nil {
+		if err := partial.Send(p.streamed); err != nil {
+			return nil, err
+		}
+	}
+
+	list := p.list
+	return &list, nil
+}
+
+func (p testCompletionProvider) ResolveCompletionItem(ctx context.Context, item CompletionItem) (*CompletionItem, error) {
+	text := "fmt.Println(${1:msg})"
+	format := InsertTextFormat_Snippet
+
+	item.InsertText = &text
+	item.InsertTextFormat = &format
+	return &item, nil
+}
+
+func TestCompletionPartialResults(t *testing.T) {
+	snippet := InsertTextFormat_Snippet
+	list := CompletionList{
+		IsIncomplete: true,
+		ItemDefaults: &CompletionItemDefaults{InsertTextFormat: &snippet},
+		Items:        []CompletionItem{{Label: "b"}},
+	}
+
+	tests := []struct {
+		token    bool
+		streamed []CompletionItem
+		response string
+		sent     []string
+	}{
+		{false, []CompletionItem{{Label: "a"}}, `{"isIncomplete":true,"itemDefaults":{"insertTextFormat":2},"items":[{"label":"b"}]}`, nil},
+		{true, nil, `{"isIncomplete":true,"itemDefaults":{"insertTextFormat":2},"items":[{"label":"b"}]}`, nil},
+		// Once items were streamed, the final response keeps isIncomplete and itemDefaults without the items.
+		{true, []CompletionItem{{Label: "a"}}, `{"isIncomplete":true,"itemDefaults":{"insertTextFormat":2},"items":[]}`, []string{`[{"label":"a"}]`, `[{"label":"b"}]`}},
+	}
+
+	for _, test := range tests {
+		rec := recordProgress(t)
+		srv := NewServer[struct{}]()
+		AddCompletionMethods(srv.Methods(), testCompletionProvider{streamed: test.streamed, list: list})
+		initializeTestServer(t, srv, snippetClientCapabilities)
+
+		params := `{"textDocument":{"uri":"file:///a.go"},"position":{"line":0,"character":0}`
+
+		if test.token {
+			params += `,"partialResultToken":"p"`
+		}
+
+		res, err := srv.processRequest(context.Background(), nil, nil, 2, Method_Completion, json.RawMessage(params+`}`))
+		if err != nil {
+			t.Fatalf("token %t: completion failed: %v", test.token, err)
+		}
+
+		if string(res) != test.response {
+			t.Errorf("token %t: response %s, want %s", test.token, res, test.response)
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.sent) {
+			t.Errorf("token %t: sent %v, want %v", test.token, got, test.sent)
+		}
+	}
+}
+
+func TestCompletionResolve(t *testing.T) {
+	tests := []struct {
+		capabilities string
+		response     string
+	}{
+		{snippetClientCapabilities, `{"label":"print","insertText":"fmt.Println(${1:msg})","insertTextFormat":2}`},
+		{`{}`, `{"label":"print","insertText":"fmt.Println(msg)","insertTextFormat":1}`},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		AddCompletionMethods(srv.Methods(), testCompletionProvider{})
+		res := initializeTestServer(t, srv, test.capabilities)
+
+		if res.Capabilities.CompletionProvider == nil || res.Capabilities.CompletionProvider.ResolveProvider == nil || !*res.Capabilities.CompletionProvider.ResolveProvider {
+			t.Errorf("%s: resolveProvider is not advertised", test.capabilities)
+		}
+
+		data, err := srv.processRequest(context.Background(), nil, nil, 2, Method_CompletionResolve, json.RawMessage(`{"label":"print"}`))
+		if err != nil {
+			t.Fatalf("%s: resolve failed: %v", test.capabilities, err)
+		}
+
+		if string(data) != test.response {
+			t.Errorf("%s: response %s, want %s", test.capabilities, data, test.response)
+		}
+	}
+}
+
+func TestCompletionDowngradeStreamedItems(t *testing.T) {
+	snippet := InsertTextFormat_Snippet
+	plain := InsertTextFormat_PlainText
+	insert := "${1:x}"
+
+	tests := []struct {
+		format   *InsertTextFormat
+		response string
+		sent     []string
+	}{
+		// Streamed items relying on the snippet default of the list are downgraded with it.
+		{&snippet, `{"isIncomplete":false,"itemDefaults":{"insertTextFormat":1},"items":[]}`, []string{`[{"label":"a","insertText":"x","insertTextFormat":1},{"label":"b","insertText":"x","insertTextFormat":1}]`}},
+		{&plain, `{"isIncomplete":false,"itemDefaults":{"insertTextFormat":1},"items":[]}`, []string{`[{"label":"a","insertText":"${1:x}","insertTextFormat":1},{"label":"b","insertText":"${1:x}","insertTextFormat":1}]`}},
+		{nil, `{"isIncomplete":false,"itemDefaults":{},"items":[]}`, []string{`[{"label":"a","insertText":"${1:x}"},{"label":"b","insertText":"${1:x}"}]`}},
+	}
+
+	for i, test := range tests {
+		rec := recordProgress(t)
+		srv := NewServer[struct{}]()
+		provider := testCompletionProvider{
+			streamed: []CompletionItem{{Label: "a", InsertText: &insert}},
+			list: CompletionList{
+				ItemDefaults: &CompletionItemDefaults{InsertTextFormat: test.format},
+				Items:        []CompletionItem{{Label: "b", InsertText: &insert}},
+			},
+		}
+
+		AddCompletionMethods(srv.Methods(), provider)
+		initializeTestServer(t, srv, `{}`)
+
+		res, err := srv.processRequest(context.Background(), nil, nil, 2, Method_Completion, json.RawMessage(`{"textDocument":{"uri":"file:///a.go"},"position":{"line":0,"character":0},"partialResultToken":"p"}`))
+		if err != nil {
+			t.Fatalf("case %d: completion failed: %v", i, err)
+		}
+
+		if string(res) != test.response {
+			t.Errorf("case %d: response %s, want %s", i, res, test.response)
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.sent) {
+			t.Errorf("case %d: sent %v, want %v", i, got, test.sent)
+		}
+	}
+}
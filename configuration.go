@@ -0,0 +1,179 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+type ConfigurationEventHandler[Ctxt any] func(srv *Server[Ctxt], section string)
+
+func addConfigurationMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(DidChangeConfigurationMethod, ClientToServer, processDidChangeConfiguration[Ctxt]))
+	set.Add(NewServerToClientRequest[Ctxt, ConfigurationParams, []json.RawMessage](ConfigurationMethod, supportsConfiguration))
+}
+
+func processDidChangeConfiguration[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidChangeConfigurationParams) error {
+	var settings map[string]json.RawMessage
+
+	// Only an object tells which sections changed, anything else (usually null in the pull model) invalidates them all.
+	if json.Unmarshal(params.Settings, &settings) != nil {
+		settings = nil
+	}
+
+	srv.lock.Lock()
+
+	for key, entry := range srv.config {
+		if settings == nil || hasSettingsKey(settings, entry.section) {
+			delete(srv.config, key)
+		}
+	}
+
+	// Bumping the generation keeps fetches still in flight from caching a stale value.
+	for section := range srv.configGen {
+		if settings == nil || hasSettingsKey(settings, section) {
+			srv.configGen[section]++
+		}
+	}
+
+	var sections []string
+	var handlers []ConfigurationEventHandler[Ctxt]
+
+	for section, subs := range srv.configSubs {
+		if settings == nil || hasSettingsKey(settings, section) {
+			for _, handler := range subs {
+				sections = append(sections, section)
+				handlers = append(handlers, handler)
+			}
+		}
+	}
+
+	srv.lock.Unlock()
+
+	for idx, handler := range handlers {
+		handler(srv, sections[idx])
+	}
+
+	return nil
+}
+
+/**
+ *	Configuration fetches the given configuration section for the scope uri (which may be empty) through
+ *	workspace/configuration and decodes it into T. Results are cached until workspace/didChangeConfiguration
+ *	reports a change of the section; a value fetched while such a change arrives is returned but not cached.
+ */
+func Configuration[Ctxt any, T any](ctx context.Context, srv *Server[Ctxt], scopeUri Uri, section string) (*T, error) {
+	key := string(scopeUri) + "\x00" + section
+
+	srv.lock.Lock()
+	entry, ok := srv.config[key]
+	gen, known := srv.configGen[section]
+
+	if !known {
+		srv.configGen[section] = gen
+	}
+
+	srv.lock.Unlock()
+
+	if !ok {
+		item := ConfigurationItem{}
+
+		if scopeUri != "" {
+			item.ScopeUri = &scopeUri
+		}
+
+		if section != "" {
+			item.Section = &section
+		}
+
+		res, err := Call[Ctxt, ConfigurationParams, []json.RawMessage](ctx, srv, ConfigurationMethod, ConfigurationParams{Items: []ConfigurationItem{item}})
+		if err != nil {
+			return nil, err
+		}
+
+		entry = configEntry{
+			section: section,
+		}
+
+		if len(*res) > 0 {
+			entry.value = (*res)[0]
+		}
+
+		srv.lock.Lock()
+
+		if srv.configGen[section] == gen {
+			srv.config[key] = entry
+		}
+
+		srv.lock.Unlock()
+	}
+
+	var val T
+
+	if trimmed := bytes.TrimSpace(entry.value); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err := json.Unmarshal(entry.value, &val); err != nil {
+			return nil, err
+		}
+	}
+
+	return &val, nil
+}
+
+/**
+ *	SubscribeConfiguration registers handler to be called when workspace/didChangeConfiguration reports a change
+ *	of section. An empty section subscribes to every change.
+ */
+func (s *Server[Ctxt]) SubscribeConfiguration(section string, handler ConfigurationEventHandler[Ctxt]) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.configSubs[section] = append(s.configSubs[section], handler)
+}
+
+func supportsConfiguration(caps ClientCapabilities) bool {
+	return caps.Workspace != nil && caps.Workspace.Configuration
+}
+
+/**
+ *	hasSettingsKey reports whether the settings object pushed by the client covers section,
+ *	which is matched by its first dotted component.
+ */
+func hasSettingsKey(settings map[string]json.RawMessage, section string) bool {
+	if section == "" {
+		return true
+	}
+
+	_, ok := settings[strings.SplitN(section, ".", 2)[0]]
+	return ok
+}
+
+type configEntry struct {
+	section string
+	value   json.RawMessage
+}
+
+// Supporting types
+const (
+	ConfigurationMethod          string = "workspace/configuration"
+	DidChangeConfigurationMethod string = "workspace/didChangeConfiguration"
+)
+
+type ConfigurationParams struct {
+	Items []ConfigurationItem `json:"items"`
+}
+
+type ConfigurationItem struct {
+	ScopeUri *Uri    `json:"scopeUri,omitempty"`
+	Section  *string `json:"section,omitempty"`
+}
+
+type DidChangeConfigurationParams struct {
+	Settings json.RawMessage `json:"settings"`
+}
+
+type DidChangeConfigurationClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
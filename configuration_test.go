@@ -0,0 +1,77 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+type testFormatSettings struct {
+	TabSize int `json:"tabSize"`
+}
+
+func TestConfigurationCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		settings string
+		// cached lists the sections still answered from the cache, the others need a workspace/configuration call.
+		cached   []string
+		notified []string
+	}{
+		{`{"go":{"format":{"tabSize":8}}}`, []string{"python"}, []string{"", "go.format"}},
+		{`{"python":{}}`, []string{"go.format"}, []string{"", "python"}},
+		{`{"rust":{}}`, []string{"go.format", "python"}, []string{""}},
+		{`null`, nil, []string{"", "go.format", "python"}},
+		{`"changed"`, nil, []string{"", "go.format", "python"}},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		srv.setState(ServerState_Initialized)
+
+		srv.config["\x00go.format"] = configEntry{section: "go.format", value: json.RawMessage(`{"tabSize":4}`)}
+		srv.config["\x00python"] = configEntry{section: "python", value: json.RawMessage(`{"tabSize":2}`)}
+		srv.configGen["go.format"] = 0
+		srv.configGen["python"] = 0
+
+		var notified []string
+
+		for _, section := range []string{"", "go.format", "python"} {
+			srv.SubscribeConfiguration(section, func(srv *Server[struct{}], section string) {
+				notified = append(notified, section)
+			})
+		}
+
+		if err := srv.processNotification(context.Background(), nil, nil, DidChangeConfigurationMethod, json.RawMessage(`{"settings":`+test.settings+`}`)); err != nil {
+			t.Fatalf("%s: didChangeConfiguration failed: %v", test.settings, err)
+		}
+
+		for _, section := range []string{"go.format", "python"} {
+			cached := false
+
+			for _, name := range test.cached {
+				cached = cached || name == section
+			}
+
+			// Without a connection, fetching a section missing from the cache fails.
+			val, err := Configuration[struct{}, testFormatSettings](context.Background(), srv, "", section)
+
+			if cached && (err != nil || val.TabSize == 0) {
+				t.Errorf("%s: section %s got %v, %v, want the cached value", test.settings, section, val, err)
+			} else if !cached && !errors.Is(err, ErrNotServing) {
+				t.Errorf("%s: section %s got %v, %v, want a workspace/configuration call", test.settings, section, val, err)
+			}
+
+			if gen := srv.configGen[section]; cached && gen != 0 || !cached && gen != 1 {
+				t.Errorf("%s: section %s has generation %d", test.settings, section, gen)
+			}
+		}
+
+		sort.Strings(notified)
+
+		if !equalStrings(notified, test.notified) {
+			t.Errorf("%s: notified %q, want %q", test.settings, notified, test.notified)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+func (s *Server[Ctxt]) processRequest(ctx context.Context, port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, method string, params json.RawMessage) (json.RawMessage, error) {
+	if err := s.checkRequestState(method); err != nil {
+		return nil, err
+	}
+
+	def, ok := s.methods.Get(method).(RequestDefinition[Ctxt])
+	if !ok || def.Direction()&ClientToServer == 0 {
+		return nil, jsonrpc.NewMethodNotFoundError(nil)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	untrack := s.trackRequest(id, cancel)
+	defer untrack()
+
+	res, err := def.Process(ctx, s, port, hdrs, id, params)
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return nil, jsonrpc.NewError(ErrorCode_RequestCancelled, "Request was cancelled.", nil)
+	}
+
+	return res, err
+}
+
+func (s *Server[Ctxt]) processNotification(ctx context.Context, port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, method string, params json.RawMessage) error {
+	if !s.acceptsNotification(method) {
+		return nil
+	}
+
+	def, ok := s.methods.Get(method).(NotificationDefinition[Ctxt])
+	if !ok || def.Direction()&ClientToServer == 0 {
+		// Unknown notifications are silently dropped as mandated by the protocol.
+		return nil
+	}
+
+	return def.Process(ctx, s, port, hdrs, params)
+}
+
+/**
+ *	checkRequestState rejects requests the protocol does not allow in the current lifecycle state:
+ *	only initialize is accepted before initialization and nothing is accepted after shutdown.
+ */
+func (s *Server[Ctxt]) checkRequestState(method string) error {
+	switch s.State() {
+	case ServerState_Uninitialized:
+		if method != InitializeMethod {
+			return jsonrpc.NewError(ErrorCode_ServerNotInitialized, "Server is not initialized.", nil)
+		}
+	case ServerState_Shutdown:
+		return jsonrpc.NewInvalidRequestError(nil)
+	}
+
+	return nil
+}
+
+/**
+ *	acceptsNotification reports whether a notification must be processed in the current lifecycle state.
+ *	Notifications other than exit are dropped before initialization and after shutdown.
+ */
+func (s *Server[Ctxt]) acceptsNotification(method string) bool {
+	if method == ExitMethod {
+		return true
+	}
+
+	switch s.State() {
+	case ServerState_Uninitialized, ServerState_Shutdown:
+		return false
+	}
+
+	return true
+}
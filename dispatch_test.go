@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	initializeTestServer runs initialize and initialized on srv with the given client capabilities in JSON.
+ */
+func initializeTestServer[Ctxt any](t *testing.T, srv *Server[Ctxt], capabilities string) InitializeResult {
+	var res InitializeResult
+
+	data, err := srv.processRequest(context.Background(), nil, nil, 1, InitializeMethod, json.RawMessage(`{"processId":null,"rootUri":null,"capabilities":`+capabilities+`}`))
+	if err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	if err = json.Unmarshal(data, &res); err != nil {
+		t.Fatalf("invalid initialize result %s: %v", data, err)
+	}
+
+	if err = srv.processNotification(context.Background(), nil, nil, InitializedMethod, json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("initialized failed: %v", err)
+	}
+
+	return res
+}
+
+func TestRequestLifecycleGating(t *testing.T) {
+	notInitialized := jsonrpc.NewError(ErrorCode_ServerNotInitialized, "Server is not initialized.", nil)
+
+	tests := []struct {
+		state  ServerState
+		method string
+		want   error
+	}{
+		{ServerState_Uninitialized, ShutdownMethod, notInitialized},
+		{ServerState_Uninitialized, Method_Hover, notInitialized},
+		{ServerState_Initialized, "unknown/method", jsonrpc.NewMethodNotFoundError(nil)},
+		{ServerState_Initialized, Method_Hover, jsonrpc.NewMethodNotFoundError(nil)},
+		{ServerState_Shutdown, ShutdownMethod, jsonrpc.NewInvalidRequestError(nil)},
+		{ServerState_Shutdown, InitializeMethod, jsonrpc.NewInvalidRequestError(nil)},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		srv.setState(test.state)
+
+		if _, err := srv.processRequest(context.Background(), nil, nil, 1, test.method, nil); !reflect.DeepEqual(err, test.want) {
+			t.Errorf("%s in state %d failed with %v, want %v", test.method, test.state, err, test.want)
+		}
+	}
+}
+
+func TestInitializeAndShutdown(t *testing.T) {
+	srv := NewServer[struct{}]()
+
+	initializeTestServer(t, srv, `{}`)
+
+	if srv.State() != ServerState_Initialized {
+		t.Fatalf("state after initialized is %d", srv.State())
+	}
+
+	if _, err := srv.processRequest(context.Background(), nil, nil, 2, InitializeMethod, json.RawMessage(`{"processId":null,"rootUri":null,"capabilities":{}}`)); err == nil {
+		t.Errorf("second initialize succeeded")
+	}
+
+	if _, err := srv.processRequest(context.Background(), nil, nil, 3, ShutdownMethod, nil); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	if srv.State() != ServerState_Shutdown {
+		t.Errorf("state after shutdown is %d", srv.State())
+	}
+}
+
+func TestNotificationLifecycleGating(t *testing.T) {
+	tests := []struct {
+		state ServerState
+		want  bool
+	}{
+		{ServerState_Uninitialized, false},
+		{ServerState_Initializing, true},
+		{ServerState_Initialized, true},
+		{ServerState_Shutdown, false},
+	}
+
+	for _, test := range tests {
+		var called bool
+
+		srv := NewServer[struct{}]()
+		srv.setState(test.state)
+		srv.Methods().Add(NewNotification("test/notify", ClientToServer, func(ctx context.Context, srv *Server[struct{}], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params Void) error {
+			called = true
+			return nil
+		}))
+
+		if err := srv.processNotification(context.Background(), nil, nil, "test/notify", json.RawMessage(`{}`)); err != nil {
+			t.Errorf("notification in state %d failed: %v", test.state, err)
+		}
+
+		if called != test.want {
+			t.Errorf("notification in state %d processed: %t, want %t", test.state, called, test.want)
+		}
+
+		if !srv.acceptsNotification(ExitMethod) {
+			t.Errorf("exit is not accepted in state %d", test.state)
+		}
+	}
+}
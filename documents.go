@@ -0,0 +1,127 @@
+package lsp
+
+import (
+	"fmt"
+	"sync"
+)
+
+/**
+ *	DocumentStore struct holds the content of the documents opened by the client.
+ *	It is safe for concurrent use and implements TextDocumentProvider so it can be registered with AddTextDocumentMethods.
+ */
+type DocumentStore struct {
+	lock sync.RWMutex
+	docs map[DocumentUri]*TextDocumentItem
+}
+
+func NewDocumentStore() *DocumentStore {
+	return &DocumentStore{
+		docs: make(map[DocumentUri]*TextDocumentItem),
+	}
+}
+
+func (s *DocumentStore) Get(uri DocumentUri) (TextDocumentItem, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if doc, ok := s.docs[uri]; ok {
+		return *doc, true
+	}
+
+	return TextDocumentItem{}, false
+}
+
+func (s *DocumentStore) Documents() []TextDocumentItem {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	res := make([]TextDocumentItem, 0, len(s.docs))
+
+	for _, doc := range s.docs {
+		res = append(res, *doc)
+	}
+
+	return res
+}
+
+func (s *DocumentStore) Open(document TextDocumentItem) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.docs[document.Uri] = &document
+}
+
+func (s *DocumentStore) Close(uri DocumentUri) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.docs, uri)
+}
+
+/**
+ *	Change applies the given content changes in order to the document, interpreting change ranges in encoding.
+ *	The document is left untouched if it is not open, if version is not greater than its current version or if a change is invalid.
+ */
+func (s *DocumentStore) Change(document VersionedTextDocumentIdentifier, changes []TextDocumentContentChangeEvent, encoding PositionEncodingKind) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	doc, ok := s.docs[document.Uri]
+	if !ok {
+		return fmt.Errorf("document '%s' is not open", document.Uri)
+	}
+
+	if document.Version <= doc.Version {
+		return fmt.Errorf("document '%s' version %d is not newer than %d", document.Uri, document.Version, doc.Version)
+	}
+
+	text := doc.Text
+
+	for _, change := range changes {
+		if change.Range == nil {
+			text = change.Text
+			continue
+		}
+
+		start, end := encoding.RangeOffsets(text, *change.Range)
+
+		if end < start {
+			return fmt.Errorf("document '%s' change range end is before its start", document.Uri)
+		}
+
+		text = text[:start] + change.Text + text[end:]
+	}
+
+	doc.Text = text
+	doc.Version = document.Version
+	return nil
+}
+
+func (s *DocumentStore) DidOpen(document TextDocumentItem) {
+	s.Open(document)
+}
+
+func (s *DocumentStore) DidClose(document TextDocumentIdentifier) {
+	s.Close(document.Uri)
+}
+
+func (s *DocumentStore) DidChange(document VersionedTextDocumentIdentifier, changes []TextDocumentContentChangeEvent, encoding PositionEncodingKind) {
+	// Invalid or out of order changes are dropped, leaving the previous content in place.
+	_ = s.Change(document, changes, encoding)
+}
+
+func (s *DocumentStore) WillSave(document TextDocumentIdentifier, reason TextDocumentSaveReason) {
+}
+
+func (s *DocumentStore) DidSave(document TextDocumentIdentifier, text *string) {
+	if text == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if doc, ok := s.docs[document.Uri]; ok {
+		doc.Text = *text
+	}
+}
@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDocumentStoreNegotiatedEncoding(t *testing.T) {
+	tests := []struct {
+		preferred PositionEncodingKind
+		offered   string
+		change    string
+	}{
+		{PositionEncodingKind_UTF8, `["utf-8","utf-16"]`, `{"start":{"line":0,"character":3},"end":{"line":0,"character":7}}`},
+		{PositionEncodingKind_UTF8, `["utf-16"]`, `{"start":{"line":0,"character":2},"end":{"line":0,"character":4}}`},
+		{PositionEncodingKind_UTF32, `["utf-32"]`, `{"start":{"line":0,"character":2},"end":{"line":0,"character":3}}`},
+	}
+
+	for _, test := range tests {
+		store := NewDocumentStore()
+		srv := NewServer[struct{}]()
+		srv.PositionEncoding = test.preferred
+		AddTextDocumentMethods(srv.Methods(), store)
+
+		initializeTestServer(t, srv, `{"general":{"positionEncodings":`+test.offered+`}}`)
+
+		notifications := []struct {
+			method string
+			params string
+		}{
+			{Method_DidOpenTextDocument, `{"textDocument":{"uri":"file:///a","languageId":"go","version":1,"text":"aé😀b\r\nx"}}`},
+			{Method_DidChangeTextDocument, `{"textDocument":{"uri":"file:///a","version":2},"contentChanges":[{"range":` + test.change + `,"text":"-"}]}`},
+		}
+
+		for _, notification := range notifications {
+			if err := srv.processNotification(context.Background(), nil, nil, notification.method, json.RawMessage(notification.params)); err != nil {
+				t.Fatalf("%s failed: %v", notification.method, err)
+			}
+		}
+
+		if doc, _ := store.Get("file:///a"); doc.Text != "aé-b\r\nx" || doc.Version != 2 {
+			t.Errorf("%s: document is %q version %d", srv.NegotiatedPositionEncoding(), doc.Text, doc.Version)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package lsp
+
+// Supporting types
+type WorkspaceEdit struct {
+	Changes           *map[DocumentUri][]TextEdit                                      `json:"changes,omitempty"`
+	DocumentChanges   *[]Choice4[TextDocumentEdit, CreateFile, RenameFile, DeleteFile] `json:"documentChanges,omitempty"`
+	ChangeAnnotations *map[ChangeAnnotationIdentifier]ChangeAnnotation                 `json:"changeAnnotations,omitempty"`
+}
+
+type ChangeAnnotation struct {
+	Label             string  `json:"label"`
+	NeedsConfirmation *bool   `json:"needsConfirmation,omitempty"`
+	Description       *string `json:"description,omitempty"`
+}
+
+type ResourceOperationKind string
+
+const (
+	ResourceOperationKind_Create ResourceOperationKind = "create"
+	ResourceOperationKind_Rename ResourceOperationKind = "rename"
+	ResourceOperationKind_Delete ResourceOperationKind = "delete"
+)
+
+type CreateFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	Uri          DocumentUri                 `json:"uri"`
+	Options      *CreateFileOptions          `json:"options,omitempty"`
+	AnnotationId *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+type CreateFileOptions struct {
+	Overwrite      bool `json:"overwrite,omitempty"`
+	IgnoreIfExists bool `json:"ignoreIfExists,omitempty"`
+}
+
+type RenameFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	OldUri       DocumentUri                 `json:"oldUri"`
+	NewUri       DocumentUri                 `json:"newUri"`
+	Options      *RenameFileOptions          `json:"options,omitempty"`
+	AnnotationId *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+type RenameFileOptions struct {
+	Overwrite      bool `json:"overwrite,omitempty"`
+	IgnoreIfExists bool `json:"ignoreIfExists,omitempty"`
+}
+
+type DeleteFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	Uri          DocumentUri                 `json:"uri"`
+	Options      *DeleteFileOptions          `json:"options,omitempty"`
+	AnnotationId *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+type DeleteFileOptions struct {
+	Recursive         bool `json:"recursive,omitempty"`
+	IgnoreIfNotExists bool `json:"ignoreIfNotExists,omitempty"`
+}
+
+func (e *TextDocumentEdit) matchesChoice() bool {
+	return e.TextDocument.Uri != ""
+}
+
+func (f *CreateFile) matchesChoice() bool {
+	return f.Kind == ResourceOperationKind_Create
+}
+
+func (f *RenameFile) matchesChoice() bool {
+	return f.Kind == ResourceOperationKind_Rename
+}
+
+func (f *DeleteFile) matchesChoice() bool {
+	return f.Kind == ResourceOperationKind_Delete
+}
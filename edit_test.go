@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDocumentChangeUnmarshal(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{`{"textDocument":{"uri":"file:///a","version":1},"edits":[]}`, 1},
+		{`{"kind":"create","uri":"file:///a"}`, 2},
+		{`{"kind":"rename","oldUri":"file:///a","newUri":"file:///b"}`, 3},
+		{`{"kind":"delete","uri":"file:///a"}`, 4},
+		{`{"kind":"delete","uri":"file:///a","options":{"recursive":true}}`, 4},
+		// Unknown fields are accepted once no option matches strictly.
+		{`{"kind":"delete","uri":"file:///a","extra":1}`, 4},
+		{`{"kind":"move","uri":"file:///a"}`, 0},
+	}
+
+	for _, test := range tests {
+		var edit WorkspaceEdit
+
+		err := json.Unmarshal([]byte(`{"documentChanges":[`+test.data+`]}`), &edit)
+
+		got := 0
+		if err == nil {
+			switch change := (*edit.DocumentChanges)[0]; {
+			case change.Opt1 != nil:
+				got = 1
+			case change.Opt2 != nil:
+				got = 2
+			case change.Opt3 != nil:
+				got = 3
+			case change.Opt4 != nil:
+				got = 4
+			}
+		}
+
+		if got != test.want {
+			t.Errorf("%s decoded as option %d (%v), want %d", test.data, got, err, test.want)
+		}
+	}
+}
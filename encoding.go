@@ -0,0 +1,164 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+ *	negotiatePositionEncoding picks the encoding to use among the ones offered by the client.
+ *	preferred is chosen when offered, otherwise the first supported offer; UTF-16 is used when the client offers none.
+ */
+func negotiatePositionEncoding(offered *[]PositionEncodingKind, preferred PositionEncodingKind) PositionEncodingKind {
+	if offered == nil {
+		return PositionEncodingKind_UTF16
+	}
+
+	for _, kind := range *offered {
+		if kind == preferred && kind.supported() {
+			return kind
+		}
+	}
+
+	for _, kind := range *offered {
+		if kind.supported() {
+			return kind
+		}
+	}
+
+	return PositionEncodingKind_UTF16
+}
+
+func (k PositionEncodingKind) supported() bool {
+	switch k {
+	case PositionEncodingKind_UTF8, PositionEncodingKind_UTF16, PositionEncodingKind_UTF32:
+		return true
+	}
+
+	return false
+}
+
+/**
+ *	OffsetOf returns the byte offset in text of the given position expressed in the k encoding.
+ *	Positions past the end of a line or of the text are clamped as required by the protocol.
+ */
+func (k PositionEncodingKind) OffsetOf(text string, pos Position) int {
+	off := lineOffset(text, pos.Line)
+
+	for units := uint(0); off < len(text) && units < pos.Character; {
+		r, size := utf8.DecodeRuneInString(text[off:])
+		if r == '\r' || r == '\n' {
+			break
+		}
+
+		units += k.runeUnits(r, size)
+		off += size
+	}
+
+	return off
+}
+
+/**
+ *	PositionOf returns the position, expressed in the k encoding, of the given byte offset in text.
+ */
+func (k PositionEncodingKind) PositionOf(text string, offset int) Position {
+	var pos Position
+
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	for off := 0; off < offset; {
+		r, size := utf8.DecodeRuneInString(text[off:])
+		if off+size > offset {
+			// offset lies within a multi-byte rune, position it at the start of the rune.
+			return pos
+		}
+
+		switch {
+		case r == '\r' && off+1 < len(text) && text[off+1] == '\n':
+			if off+1 == offset {
+				return pos
+			}
+
+			pos.Line++
+			pos.Character = 0
+			off += 2
+			continue
+		case r == '\r' || r == '\n':
+			pos.Line++
+			pos.Character = 0
+		default:
+			pos.Character += k.runeUnits(r, size)
+		}
+
+		off += size
+	}
+
+	return pos
+}
+
+/**
+ *	Length returns the length of text in code units of the k encoding.
+ */
+func (k PositionEncodingKind) Length(text string) uint {
+	var res uint
+
+	for off := 0; off < len(text); {
+		r, size := utf8.DecodeRuneInString(text[off:])
+		res += k.runeUnits(r, size)
+		off += size
+	}
+
+	return res
+}
+
+func (k PositionEncodingKind) RangeOffsets(text string, rng Range) (int, int) {
+	return k.OffsetOf(text, rng.Start), k.OffsetOf(text, rng.End)
+}
+
+func (k PositionEncodingKind) RangeOf(text string, start int, end int) Range {
+	return Range{
+		Start: k.PositionOf(text, start),
+		End:   k.PositionOf(text, end),
+	}
+}
+
+func (k PositionEncodingKind) runeUnits(r rune, size int) uint {
+	switch k {
+	case PositionEncodingKind_UTF8:
+		return uint(size)
+	case PositionEncodingKind_UTF32:
+		return 1
+	}
+
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+/**
+ *	lineOffset returns the byte offset in text of the start of the given line, or the length of text if it has fewer lines.
+ */
+func lineOffset(text string, line uint) int {
+	off := 0
+
+	for ; line > 0; line-- {
+		idx := strings.IndexAny(text[off:], "\r\n")
+		if idx < 0 {
+			return len(text)
+		}
+
+		off += idx
+
+		if text[off] == '\r' && off+1 < len(text) && text[off+1] == '\n' {
+			off += 2
+		} else {
+			off++
+		}
+	}
+
+	return off
+}
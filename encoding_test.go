@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+const encodingTestText = "aé😀b\r\nx\n\r€😀\ny"
+
+var encodingTestKinds = []PositionEncodingKind{PositionEncodingKind_UTF8, PositionEncodingKind_UTF16, PositionEncodingKind_UTF32}
+
+func TestPositionOf(t *testing.T) {
+	tests := []struct {
+		offset int
+		want   [3]Position
+	}{
+		{0, [3]Position{{0, 0}, {0, 0}, {0, 0}}},
+		{1, [3]Position{{0, 1}, {0, 1}, {0, 1}}},
+		{3, [3]Position{{0, 3}, {0, 2}, {0, 2}}},
+		{7, [3]Position{{0, 7}, {0, 4}, {0, 3}}},
+		{8, [3]Position{{0, 8}, {0, 5}, {0, 4}}},
+		{10, [3]Position{{1, 0}, {1, 0}, {1, 0}}},
+		{12, [3]Position{{2, 0}, {2, 0}, {2, 0}}},
+		{13, [3]Position{{3, 0}, {3, 0}, {3, 0}}},
+		{16, [3]Position{{3, 3}, {3, 1}, {3, 1}}},
+		{20, [3]Position{{3, 7}, {3, 3}, {3, 2}}},
+		{22, [3]Position{{4, 1}, {4, 1}, {4, 1}}},
+		// Within a multi-byte rune, between CR and LF and past the end.
+		{5, [3]Position{{0, 3}, {0, 2}, {0, 2}}},
+		{9, [3]Position{{0, 8}, {0, 5}, {0, 4}}},
+		{99, [3]Position{{4, 1}, {4, 1}, {4, 1}}},
+	}
+
+	for _, test := range tests {
+		for idx, kind := range encodingTestKinds {
+			if got := kind.PositionOf(encodingTestText, test.offset); got != test.want[idx] {
+				t.Errorf("%s: PositionOf(%d) = %v, want %v", kind, test.offset, got, test.want[idx])
+			}
+		}
+	}
+}
+
+func TestOffsetOf(t *testing.T) {
+	tests := []struct {
+		pos  [3]Position
+		want int
+	}{
+		{[3]Position{{0, 7}, {0, 4}, {0, 3}}, 7},
+		{[3]Position{{3, 3}, {3, 1}, {3, 1}}, 16},
+		{[3]Position{{3, 7}, {3, 3}, {3, 2}}, 20},
+		// Positions past the end of a line or of the text are clamped.
+		{[3]Position{{0, 99}, {0, 99}, {0, 99}}, 8},
+		{[3]Position{{2, 5}, {2, 5}, {2, 5}}, 12},
+		{[3]Position{{9, 0}, {9, 0}, {9, 0}}, 22},
+	}
+
+	for _, test := range tests {
+		for idx, kind := range encodingTestKinds {
+			if got := kind.OffsetOf(encodingTestText, test.pos[idx]); got != test.want {
+				t.Errorf("%s: OffsetOf(%v) = %d, want %d", kind, test.pos[idx], got, test.want)
+			}
+		}
+	}
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	for _, kind := range encodingTestKinds {
+		for off := 0; off <= len(encodingTestText); off++ {
+			// Offsets within a rune or between CR and LF have no position of their own.
+			if off < len(encodingTestText) && !utf8.RuneStart(encodingTestText[off]) {
+				continue
+			}
+
+			if off > 0 && encodingTestText[off-1] == '\r' && off < len(encodingTestText) && encodingTestText[off] == '\n' {
+				continue
+			}
+
+			pos := kind.PositionOf(encodingTestText, off)
+
+			if got := kind.OffsetOf(encodingTestText, pos); got != off {
+				t.Errorf("%s: OffsetOf(PositionOf(%d) = %v) = %d", kind, off, pos, got)
+			}
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	want := [3]uint{8, 5, 4}
+
+	for idx, kind := range encodingTestKinds {
+		if got := kind.Length("aé😀b"); got != want[idx] {
+			t.Errorf("%s: Length = %d, want %d", kind, got, want[idx])
+		}
+	}
+}
+
+func TestNegotiatePositionEncoding(t *testing.T) {
+	tests := []struct {
+		offered   *[]PositionEncodingKind
+		preferred PositionEncodingKind
+		want      PositionEncodingKind
+	}{
+		{nil, PositionEncodingKind_UTF8, PositionEncodingKind_UTF16},
+		{&[]PositionEncodingKind{PositionEncodingKind_UTF16, PositionEncodingKind_UTF8}, PositionEncodingKind_UTF8, PositionEncodingKind_UTF8},
+		{&[]PositionEncodingKind{PositionEncodingKind_UTF32, PositionEncodingKind_UTF16}, PositionEncodingKind_UTF8, PositionEncodingKind_UTF32},
+		{&[]PositionEncodingKind{"utf-7"}, PositionEncodingKind_UTF8, PositionEncodingKind_UTF16},
+	}
+
+	for _, test := range tests {
+		if got := negotiatePositionEncoding(test.offered, test.preferred); got != test.want {
+			t.Errorf("negotiatePositionEncoding(%v, %s) = %s, want %s", test.offered, test.preferred, got, test.want)
+		}
+	}
+}
+
+func TestDocumentStoreChange(t *testing.T) {
+	tests := []struct {
+		kind PositionEncodingKind
+		rng  Range
+	}{
+		{PositionEncodingKind_UTF8, Range{Position{0, 3}, Position{0, 7}}},
+		{PositionEncodingKind_UTF16, Range{Position{0, 2}, Position{0, 4}}},
+		{PositionEncodingKind_UTF32, Range{Position{0, 2}, Position{0, 3}}},
+	}
+
+	for _, test := range tests {
+		store := NewDocumentStore()
+		store.Open(TextDocumentItem{Uri: "file:///a", Version: 1, Text: "aé😀b\r\nx"})
+
+		doc := VersionedTextDocumentIdentifier{Version: 2}
+		doc.Uri = "file:///a"
+
+		if err := store.Change(doc, []TextDocumentContentChangeEvent{{Range: &test.rng, Text: "-"}}, test.kind); err != nil {
+			t.Fatalf("%s: Change failed: %v", test.kind, err)
+		}
+
+		if got, _ := store.Get("file:///a"); got.Text != "aé-b\r\nx" {
+			t.Errorf("%s: text is %q", test.kind, got.Text)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package lsp
 
-import "github.com/trwk76/jsonrpc"
+import (
+	"errors"
+
+	"github.com/trwk76/jsonrpc"
+)
 
 const (
 	ErrorCode_ServerNotInitialized       jsonrpc.ErrorCode = -32002
@@ -11,3 +15,9 @@ const (
 	ErrorCode_ContentModified            jsonrpc.ErrorCode = -32801
 	ErrorCode_RequestCancelled           jsonrpc.ErrorCode = -32800
 )
+
+var (
+	ErrNotServing               = errors.New("server is not serving a connection")
+	ErrClientUnsupported        = errors.New("client does not support the method")
+	ErrStaticRegistrationClosed = errors.New("static capabilities can only be registered before initialize")
+)
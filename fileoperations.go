@@ -0,0 +1,194 @@
+package lsp
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	FileOperationsProvider interface handles the file operations performed by the client.
+ *	FileOperations returns the filters of the operations the provider is interested in; operations without filters
+ *	are neither registered nor advertised, and the files passed to the other methods always match the filters.
+ */
+type FileOperationsProvider interface {
+	FileOperations() WorkspaceFileOperationsServerCapabilities
+	WillCreateFiles(ctx context.Context, files []FileCreate) (*WorkspaceEdit, error)
+	DidCreateFiles(files []FileCreate)
+	WillRenameFiles(ctx context.Context, files []FileRename) (*WorkspaceEdit, error)
+	DidRenameFiles(files []FileRename)
+	WillDeleteFiles(ctx context.Context, files []FileDelete) (*WorkspaceEdit, error)
+	DidDeleteFiles(files []FileDelete)
+}
+
+/**
+ *	AddFileOperationsMethods adds the file operation methods dispatching to provider to the set and advertises their filters.
+ */
+func AddFileOperationsMethods[Ctxt any](set *MethodSet[Ctxt], provider FileOperationsProvider) {
+	ops := provider.FileOperations()
+
+	if ops.WillCreate != nil {
+		set.Add(NewRequest(WillCreateFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params CreateFilesParams) (*WorkspaceEdit, error) {
+			if files := filterFileCreates(*ops.WillCreate, params.Files); len(files) > 0 {
+				return provider.WillCreateFiles(ctx, files)
+			}
+
+			return nil, nil
+		}))
+	}
+
+	if ops.DidCreate != nil {
+		set.Add(NewNotification(DidCreateFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params CreateFilesParams) error {
+			if files := filterFileCreates(*ops.DidCreate, params.Files); len(files) > 0 {
+				provider.DidCreateFiles(files)
+			}
+
+			return nil
+		}))
+	}
+
+	if ops.WillRename != nil {
+		set.Add(NewRequest(WillRenameFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params RenameFilesParams) (*WorkspaceEdit, error) {
+			if files := filterFileRenames(*ops.WillRename, params.Files); len(files) > 0 {
+				return provider.WillRenameFiles(ctx, files)
+			}
+
+			return nil, nil
+		}))
+	}
+
+	if ops.DidRename != nil {
+		set.Add(NewNotification(DidRenameFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params RenameFilesParams) error {
+			if files := filterFileRenames(*ops.DidRename, params.Files); len(files) > 0 {
+				provider.DidRenameFiles(files)
+			}
+
+			return nil
+		}))
+	}
+
+	if ops.WillDelete != nil {
+		set.Add(NewRequest(WillDeleteFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params DeleteFilesParams) (*WorkspaceEdit, error) {
+			if files := filterFileDeletes(*ops.WillDelete, params.Files); len(files) > 0 {
+				return provider.WillDeleteFiles(ctx, files)
+			}
+
+			return nil, nil
+		}))
+	}
+
+	if ops.DidDelete != nil {
+		set.Add(NewNotification(DidDeleteFilesMethod, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DeleteFilesParams) error {
+			if files := filterFileDeletes(*ops.DidDelete, params.Files); len(files) > 0 {
+				provider.DidDeleteFiles(files)
+			}
+
+			return nil
+		}))
+	}
+
+	set.AddCapabilities(func(caps *ServerCapabilities) {
+		var ws WorkspaceOptions
+
+		if caps.Workspace != nil {
+			ws = *caps.Workspace
+		}
+
+		if ws.FileOperations == nil {
+			ws.FileOperations = &ops
+		}
+
+		caps.Workspace = &ws
+	})
+}
+
+func filterFileCreates(opts FileOperationRegistrationOptions, files []FileCreate) []FileCreate {
+	var res []FileCreate
+
+	for _, file := range files {
+		if matchesFileOperation(opts, file.Uri) {
+			res = append(res, file)
+		}
+	}
+
+	return res
+}
+
+func filterFileRenames(opts FileOperationRegistrationOptions, files []FileRename) []FileRename {
+	var res []FileRename
+
+	for _, file := range files {
+		if matchesFileOperation(opts, file.OldUri) || matchesFileOperation(opts, file.NewUri) {
+			res = append(res, file)
+		}
+	}
+
+	return res
+}
+
+func filterFileDeletes(opts FileOperationRegistrationOptions, files []FileDelete) []FileDelete {
+	var res []FileDelete
+
+	for _, file := range files {
+		if matchesFileOperation(opts, file.Uri) {
+			res = append(res, file)
+		}
+	}
+
+	return res
+}
+
+/**
+ *	matchesFileOperation matches uri against the filters as a file or a folder depending on what it is on disk.
+ *	When uri cannot be found locally, for instance before a creation, it is matched as both.
+ */
+func matchesFileOperation(opts FileOperationRegistrationOptions, uri string) bool {
+	if parsed, err := url.Parse(uri); err == nil && parsed.Scheme == "file" {
+		if info, err := os.Stat(parsed.Path); err == nil {
+			if info.IsDir() {
+				return opts.Matches(uri, FileOperationPatternKind_Folder)
+			}
+
+			return opts.Matches(uri, FileOperationPatternKind_File)
+		}
+	}
+
+	return opts.Matches(uri, FileOperationPatternKind_File) || opts.Matches(uri, FileOperationPatternKind_Folder)
+}
+
+// Supporting types
+const (
+	WillCreateFilesMethod string = "workspace/willCreateFiles"
+	DidCreateFilesMethod  string = "workspace/didCreateFiles"
+	WillRenameFilesMethod string = "workspace/willRenameFiles"
+	DidRenameFilesMethod  string = "workspace/didRenameFiles"
+	WillDeleteFilesMethod string = "workspace/willDeleteFiles"
+	DidDeleteFilesMethod  string = "workspace/didDeleteFiles"
+)
+
+type CreateFilesParams struct {
+	Files []FileCreate `json:"files"`
+}
+
+type FileCreate struct {
+	Uri string `json:"uri"`
+}
+
+type RenameFilesParams struct {
+	Files []FileRename `json:"files"`
+}
+
+type FileRename struct {
+	OldUri string `json:"oldUri"`
+	NewUri string `json:"newUri"`
+}
+
+type DeleteFilesParams struct {
+	Files []FileDelete `json:"files"`
+}
+
+type FileDelete struct {
+	Uri string `json:"uri"`
+}
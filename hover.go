@@ -0,0 +1,176 @@
+package lsp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	HoverProvider interface answers textDocument/hover requests.
+ *	Markdown contents, including the text of marked strings, are cleaned of the HTML tags the client does not allow,
+ *	or converted to plain text markup content when the client does not support markdown.
+ */
+type HoverProvider interface {
+	Hover(ctx context.Context, params HoverParams) (*Hover, error)
+}
+
+/**
+ *	AddHoverMethods adds the hover method dispatching to provider to the set and advertises it.
+ */
+func AddHoverMethods[Ctxt any](set *MethodSet[Ctxt], provider HoverProvider) {
+	set.Add(NewRequest(Method_Hover, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params HoverParams) (*Hover, error) {
+		hover, err := provider.Hover(ctx, params)
+		if err != nil || hover == nil {
+			return nil, err
+		}
+
+		res := *hover
+		caps := srv.ClientInfo().Capabilites
+
+		switch {
+		case res.Contents.Opt1 != nil:
+			content := renderMarkupContent(*res.Contents.Opt1, caps)
+			res.Contents = Choice3[MarkupContent, MarkedString, []MarkedString]{Opt1: &content}
+		case !supportsHoverMarkdown(caps):
+			// Marked strings are rendered as markdown by clients, plain text must be sent as such.
+			content := markedStringsToPlainText(res.Contents)
+			res.Contents = Choice3[MarkupContent, MarkedString, []MarkedString]{Opt1: &content}
+		case res.Contents.Opt2 != nil:
+			str := renderMarkedString(*res.Contents.Opt2, caps)
+			res.Contents = Choice3[MarkupContent, MarkedString, []MarkedString]{Opt2: &str}
+		case res.Contents.Opt3 != nil:
+			strs := make([]MarkedString, len(*res.Contents.Opt3))
+
+			for idx, str := range *res.Contents.Opt3 {
+				strs[idx] = renderMarkedString(str, caps)
+			}
+
+			res.Contents = Choice3[MarkupContent, MarkedString, []MarkedString]{Opt3: &strs}
+		}
+
+		return &res, nil
+	}))
+
+	set.AddCapabilities(func(caps *ServerCapabilities) {
+		if caps.HoverProvider == nil {
+			caps.HoverProvider = &HoverOptions{}
+		}
+	})
+}
+
+/**
+ *	renderMarkupContent adapts markdown content to the markdown support of the client.
+ */
+func renderMarkupContent(content MarkupContent, caps ClientCapabilities) MarkupContent {
+	if content.Kind != MarkupKind_Markdown {
+		return content
+	}
+
+	if !supportsHoverMarkdown(caps) {
+		return MarkupContent{
+			Kind:  MarkupKind_PlainText,
+			Value: MarkdownToPlainText(content.Value),
+		}
+	}
+
+	var allowed []string
+
+	if caps.General != nil && caps.General.Markdown != nil && caps.General.Markdown.AllowedTags != nil {
+		allowed = *caps.General.Markdown.AllowedTags
+	}
+
+	return MarkupContent{
+		Kind:  MarkupKind_Markdown,
+		Value: CleanMarkdown(content.Value, allowed),
+	}
+}
+
+/**
+ *	renderMarkedString adapts the markdown text of str like renderMarkupContent; code blocks are left untouched.
+ */
+func renderMarkedString(str MarkedString, caps ClientCapabilities) MarkedString {
+	if str.Opt1 == nil {
+		return str
+	}
+
+	content := renderMarkupContent(MarkupContent{Kind: MarkupKind_Markdown, Value: *str.Opt1}, caps)
+	return MarkedString{Opt1: &content.Value}
+}
+
+/**
+ *	markedStringsToPlainText joins the marked strings of contents into plain text content, separated by blank lines.
+ */
+func markedStringsToPlainText(contents Choice3[MarkupContent, MarkedString, []MarkedString]) MarkupContent {
+	var strs []MarkedString
+	var parts []string
+
+	if contents.Opt2 != nil {
+		strs = []MarkedString{*contents.Opt2}
+	} else if contents.Opt3 != nil {
+		strs = *contents.Opt3
+	}
+
+	for _, str := range strs {
+		switch {
+		case str.Opt1 != nil:
+			parts = append(parts, MarkdownToPlainText(*str.Opt1))
+		case str.Opt2 != nil:
+			parts = append(parts, str.Opt2.Value)
+		}
+	}
+
+	return MarkupContent{
+		Kind:  MarkupKind_PlainText,
+		Value: strings.Join(parts, "\n\n"),
+	}
+}
+
+func supportsHoverMarkdown(caps ClientCapabilities) bool {
+	if caps.TextDocument == nil || caps.TextDocument.Hover == nil || caps.TextDocument.Hover.ContentFormat == nil {
+		return false
+	}
+
+	for _, kind := range *caps.TextDocument.Hover.ContentFormat {
+		if kind == MarkupKind_Markdown {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Supporting types
+const (
+	Method_Hover string = "textDocument/hover"
+)
+
+type HoverClientCapabilities struct {
+	DynamicRegistration bool          `json:"dynamicRegistration,omitempty"`
+	ContentFormat       *[]MarkupKind `json:"contentFormat,omitempty"`
+}
+
+type HoverOptions struct {
+	WorkDoneProgressOptions
+}
+
+type HoverParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+}
+
+type Hover struct {
+	Contents Choice3[MarkupContent, MarkedString, []MarkedString] `json:"contents"`
+	Range    *Range                                               `json:"range,omitempty"`
+}
+
+/**
+ *	MarkedString is either markdown text or a code block in the given language. It is deprecated in favour of MarkupContent.
+ */
+type MarkedString = Choice2[string, LanguageString]
+
+type LanguageString struct {
+	Language string `json:"language"`
+	Value    string `json:"value"`
+}
@@ -66,9 +66,35 @@ func processInitialize[Ctxt any](ctx context.Context, srv *Server[Ctxt], port js
 	}
 
 	srv.clientInfo.Capabilites = params.Capabilities
+
+	if params.Trace != nil {
+		srv.setTrace(*params.Trace)
+	}
+
+	srv.seedWorkspaceFolders(params)
+
+	var offered *[]PositionEncodingKind
+
+	if params.Capabilities.General != nil {
+		offered = params.Capabilities.General.PositionEncodings
+	}
+
+	encoding := negotiatePositionEncoding(offered, srv.PositionEncoding)
+
+	srv.lock.Lock()
+	srv.posEncoding = encoding
+	srv.lock.Unlock()
+
 	srv.setState(ServerState_Initializing)
 
-	return &InitializeResult{}, nil
+	caps := srv.methods.Capabilities(srv.Capabilities)
+	srv.applyStaticRegistrations(&caps)
+	caps.PositionEncoding = &encoding
+
+	return &InitializeResult{
+		Capabilities: caps,
+		ServerInfo:   srv.ServerInfo,
+	}, nil
 }
 
 func processInitialized[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params InitializedParams) error {
@@ -127,7 +153,7 @@ const (
 )
 
 type ClientCapabilities struct {
-	// Workspace        *WorkspaceClientCapabilities        `json:"workspace,omitempty"`
+	Workspace    *WorkspaceClientCapabilities    `json:"workspace,omitempty"`
 	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
 	// NotebookDocument *NotebookDocumentClientCapabilities `json:"notebookDocument,omitempty"`
 	Window       *WindowClientCapabilities  `json:"window,omitempty"`
@@ -168,13 +194,13 @@ type ServerCapabilities struct {
 	PositionEncoding *PositionEncodingKind    `json:"positionEncoding,omitempty"`
 	TextDocumentSync *TextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
 	// NotebookDocumentSync             *NotebookDocumentSyncRegistrationOptions   `json:"notebookDocumentSync,omitempty"`
-	// CompletionProvider               *CompletionOptions                                                               `json:"completionProvider,omitempty"`
-	// HoverProvider                    *HoverOptions                                                     `json:"hoverProvider,omitempty"`
-	// SignatureHelpProvider            *SignatureHelpOptions                                                            `json:"signatureHelpProvider,omitempty"`
-	// DeclarationProvider *DeclarationRegistrationOptions `json:"declarationProvider,omitempty"`
-	// DefinitionProvider               *DefinitionOptions                                                `json:"definitionProvider,omitempty"`
-	// TypeDefinitionProvider           *TypeDefinitionRegistrationOptions         `json:"typeDefinitionProvider,omitempty"`
-	// ImplementationProvider           *ImplementationRegistrationOptions         `json:"implementationProvider,omitempty"`
+	CompletionProvider     *CompletionOptions                 `json:"completionProvider,omitempty"`
+	HoverProvider          *HoverOptions                      `json:"hoverProvider,omitempty"`
+	SignatureHelpProvider  *SignatureHelpOptions              `json:"signatureHelpProvider,omitempty"`
+	DeclarationProvider    *DeclarationRegistrationOptions    `json:"declarationProvider,omitempty"`
+	DefinitionProvider     *DefinitionOptions                 `json:"definitionProvider,omitempty"`
+	TypeDefinitionProvider *TypeDefinitionRegistrationOptions `json:"typeDefinitionProvider,omitempty"`
+	ImplementationProvider *ImplementationRegistrationOptions `json:"implementationProvider,omitempty"`
 	// ReferencesProvider               *ReferenceOptions                                                 `json:"referencesProvider,omitempty"`
 	// DocumentHighlightProvider        *DocumentHighlightOptions                                         `json:"documentHighlightProvider,omitempty"`
 	// DocumentSymbolProvider           *DocumentSymbolOptions                                            `json:"documentSymbolProvider,omitempty"`
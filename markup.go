@@ -0,0 +1,153 @@
+package lsp
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var markdownTagExpr = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9-]*)(\s[^<>]*)?/?>`)
+
+/**
+ *	CleanMarkdown escapes the HTML tags of md that are not in allowedTags so that they are displayed literally.
+ *	Code blocks and code spans are left untouched.
+ */
+func CleanMarkdown(md string, allowedTags []string) string {
+	allowed := make(map[string]bool, len(allowedTags))
+
+	for _, tag := range allowedTags {
+		allowed[strings.ToLower(tag)] = true
+	}
+
+	clean := func(text string) string {
+		return markdownTagExpr.ReplaceAllStringFunc(text, func(tag string) string {
+			if allowed[strings.ToLower(markdownTagExpr.FindStringSubmatch(tag)[1])] {
+				return tag
+			}
+
+			return "&lt;" + tag[1:]
+		})
+	}
+
+	lines := strings.Split(md, "\n")
+	fenced := false
+
+	for idx, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		// Odd parts are code spans.
+		parts := strings.Split(line, "`")
+
+		for pidx := 0; pidx < len(parts); pidx += 2 {
+			parts[pidx] = clean(parts[pidx])
+		}
+
+		lines[idx] = strings.Join(parts, "`")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/**
+ *	MarkdownToPlainText returns a readable plain text version of md: code fences, headings and quote markers,
+ *	emphasis, links, HTML tags and escapes are removed while code blocks and code spans are kept verbatim.
+ */
+func MarkdownToPlainText(md string) string {
+	lines := strings.Split(md, "\n")
+	res := lines[:0]
+	fenced := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+
+		if fenced {
+			res = append(res, line)
+			continue
+		}
+
+		if markdownHeadingExpr.MatchString(line) {
+			line = markdownHeadingExpr.ReplaceAllString(line, "")
+			line = markdownHeadingEndExpr.ReplaceAllString(line, "")
+		}
+		line = markdownQuoteExpr.ReplaceAllString(line, "")
+
+		// Odd parts are code spans, whose delimiters are dropped.
+		parts := strings.Split(line, "`")
+
+		for pidx := 0; pidx < len(parts); pidx += 2 {
+			parts[pidx] = inlineMarkdownToPlainText(parts[pidx])
+		}
+
+		res = append(res, strings.Join(parts, ""))
+	}
+
+	return strings.Join(res, "\n")
+}
+
+var (
+	markdownHeadingExpr    = regexp.MustCompile(`^ {0,3}#{1,6}(\s+|$)`)
+	markdownHeadingEndExpr = regexp.MustCompile(`\s+#+\s*$`)
+	markdownQuoteExpr      = regexp.MustCompile(`^ {0,3}(> ?)+`)
+	markdownEscapeExpr     = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!<>~|])")
+	markdownLinkExpr       = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownStrongExpr     = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__|~~(\S(?:.*?\S)?)~~`)
+	markdownEmphasisExpr   = regexp.MustCompile(`(^|[^\w*])\*(\S(?:[^*]*\S)?)\*|(^|[^\w_])_(\S(?:[^_]*\S)?)_(\W|$)`)
+)
+
+/**
+ *	inlineMarkdownToPlainText strips the inline markup of text, which is outside of code spans.
+ */
+func inlineMarkdownToPlainText(text string) string {
+	var escaped []string
+
+	// Escaped characters are set aside so that they are not taken for markup.
+	text = markdownEscapeExpr.ReplaceAllStringFunc(text, func(esc string) string {
+		escaped = append(escaped, esc[1:])
+		return "\uE000"
+	})
+
+	text = markdownTagExpr.ReplaceAllString(text, "")
+	text = markdownLinkExpr.ReplaceAllString(text, "$1")
+	text = markdownStrongExpr.ReplaceAllString(text, "$1$2$3")
+
+	// Matches consume the character following an underscore emphasis, which may start the next one.
+	for {
+		next := markdownEmphasisExpr.ReplaceAllString(text, "$1$2$3$4$5")
+		if next == text {
+			break
+		}
+
+		text = next
+	}
+
+	text = html.UnescapeString(text)
+
+	for _, esc := range escaped {
+		text = strings.Replace(text, "\uE000", esc, 1)
+	}
+
+	return text
+}
+
+// Supporting types
+type MarkupKind string
+
+const (
+	MarkupKind_PlainText MarkupKind = "plaintext"
+	MarkupKind_Markdown  MarkupKind = "markdown"
+)
+
+type MarkupContent struct {
+	Kind  MarkupKind `json:"kind"`
+	Value string     `json:"value"`
+}
@@ -0,0 +1,82 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestMarkdownToPlainText(t *testing.T) {
+	tests := []struct {
+		md   string
+		want string
+	}{
+		{"# Title #", "Title"},
+		{"> quoted", "quoted"},
+		{"**strong** and __strong__", "strong and strong"},
+		{"*a* *b*", "a b"},
+		{"_a_ _b_", "a b"},
+		{"_a_,_b_", "a,b"},
+		{"snake_case_name", "snake_case_name"},
+		{"2*3*4", "2*3*4"},
+		{`\*literal\*`, "*literal*"},
+		{"[link](http://example.com)", "link"},
+		{"a <b>tag</b> &amp; `<code> _x_`", "a tag & <code> _x_"},
+		{"```go\n_x_ <y>\n```", "_x_ <y>"},
+	}
+
+	for _, test := range tests {
+		if got := MarkdownToPlainText(test.md); got != test.want {
+			t.Errorf("MarkdownToPlainText(%q) = %q, want %q", test.md, got, test.want)
+		}
+	}
+}
+
+type testHoverProvider struct {
+	contents Choice3[MarkupContent, MarkedString, []MarkedString]
+}
+
+func (p testHoverProvider) Hover(ctx context.Context, params HoverParams) (*Hover, error) {
+	return &Hover{Contents: p.contents}, nil
+}
+
+func TestHoverMarkedStrings(t *testing.T) {
+	text := "_a_ <b>b</b>"
+	code := LanguageString{Language: "go", Value: "func _x_()"}
+	single := MarkedString{Opt1: &text}
+	list := []MarkedString{{Opt2: &code}, {Opt1: &text}}
+
+	tests := []struct {
+		contents     Choice3[MarkupContent, MarkedString, []MarkedString]
+		capabilities string
+		want         string
+	}{
+		{Choice3[MarkupContent, MarkedString, []MarkedString]{Opt2: &single}, `{}`, `{"kind":"plaintext","value":"a b"}`},
+		{Choice3[MarkupContent, MarkedString, []MarkedString]{Opt3: &list}, `{}`, `{"kind":"plaintext","value":"func _x_()\n\na b"}`},
+		{Choice3[MarkupContent, MarkedString, []MarkedString]{Opt2: &single}, `{"textDocument":{"hover":{"contentFormat":["markdown"]}}}`, `"_a_ \u0026lt;b\u003eb\u0026lt;/b\u003e"`},
+		{Choice3[MarkupContent, MarkedString, []MarkedString]{Opt3: &list}, `{"textDocument":{"hover":{"contentFormat":["markdown"]}}}`, `[{"language":"go","value":"func _x_()"},"_a_ \u0026lt;b\u003eb\u0026lt;/b\u003e"]`},
+	}
+
+	for i, test := range tests {
+		var res struct {
+			Contents json.RawMessage `json:"contents"`
+		}
+
+		srv := NewServer[struct{}]()
+		AddHoverMethods(srv.Methods(), testHoverProvider{contents: test.contents})
+		initializeTestServer(t, srv, test.capabilities)
+
+		data, err := srv.processRequest(context.Background(), nil, nil, 2, Method_Hover, json.RawMessage(`{"textDocument":{"uri":"file:///a.go"},"position":{"line":0,"character":0}}`))
+		if err != nil {
+			t.Fatalf("case %d: hover failed: %v", i, err)
+		}
+
+		if err = json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("case %d: invalid hover %s: %v", i, data, err)
+		}
+
+		if string(res.Contents) != test.want {
+			t.Errorf("case %d: contents %s, want %s", i, res.Contents, test.want)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/**
+ *	Glob struct holds a compiled glob pattern using the protocol syntax:
+ *	* matches within a path segment, ? matches one character, ** matches any number of segments,
+ *	{a,b} groups alternatives and [a-z] / [!a-z] match a character range.
+ */
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// globCacheSize bounds the number of compiled globs kept by CompileGlob; the cache is emptied once it is full.
+const globCacheSize = 256
+
+var globCacheLock sync.Mutex
+var globCache = map[string]*Glob{}
+
+func CompileGlob(pattern string, ignoreCase bool) (*Glob, error) {
+	key := fmt.Sprintf("%t:%s", ignoreCase, pattern)
+
+	globCacheLock.Lock()
+	glob, ok := globCache[key]
+	globCacheLock.Unlock()
+
+	if ok {
+		return glob, nil
+	}
+
+	expr, err := globExpr(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob '%s': %w", pattern, err)
+	}
+
+	glob = &Glob{
+		pattern: pattern,
+		re:      re,
+	}
+
+	globCacheLock.Lock()
+	defer globCacheLock.Unlock()
+
+	if len(globCache) >= globCacheSize {
+		globCache = map[string]*Glob{}
+	}
+
+	globCache[key] = glob
+	return glob, nil
+}
+
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+func (g *Glob) Match(path string) bool {
+	return g.re.MatchString(path)
+}
+
+/**
+ *	MatchGlob reports whether path matches pattern; an invalid pattern matches nothing.
+ */
+func MatchGlob(pattern string, path string, ignoreCase bool) bool {
+	glob, err := CompileGlob(pattern, ignoreCase)
+	if err != nil {
+		return false
+	}
+
+	return glob.Match(path)
+}
+
+func globExpr(pattern string) (string, error) {
+	var buf strings.Builder
+	var depth int
+
+	buf.WriteString("^")
+
+	for idx := 0; idx < len(pattern); idx++ {
+		ch := pattern[idx]
+
+		switch ch {
+		case '*':
+			if idx+1 < len(pattern) && pattern[idx+1] == '*' {
+				idx++
+
+				if idx+1 < len(pattern) && pattern[idx+1] == '/' {
+					idx++
+					buf.WriteString("(?:.*/)?")
+				} else {
+					buf.WriteString(".*")
+				}
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '/':
+			if pattern[idx+1:] == "**" {
+				// A trailing /** also matches the directory itself.
+				buf.WriteString("(?:/.*)?")
+				idx = len(pattern)
+			} else {
+				buf.WriteString("/")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '{':
+			depth++
+			buf.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				return "", fmt.Errorf("invalid glob '%s': unbalanced '}'", pattern)
+			}
+
+			depth--
+			buf.WriteString(")")
+		case ',':
+			if depth > 0 {
+				buf.WriteString("|")
+			} else {
+				buf.WriteString(",")
+			}
+		case '[':
+			start := idx + 1
+
+			if start < len(pattern) && pattern[start] == '!' {
+				start++
+			}
+
+			// A ] right after the opening bracket is part of the range.
+			if start < len(pattern) && pattern[start] == ']' {
+				start++
+			}
+
+			end := strings.IndexByte(pattern[start:], ']')
+			if end >= 0 {
+				end += start - idx - 1
+			}
+
+			if end < 0 {
+				return "", fmt.Errorf("invalid glob '%s': unterminated character range", pattern)
+			}
+
+			class := pattern[idx+1 : idx+1+end]
+
+			if strings.HasPrefix(class, "!") {
+				buf.WriteString("[^/")
+				class = class[1:]
+			} else {
+				buf.WriteString("[")
+			}
+
+			for cidx := 0; cidx < len(class); cidx++ {
+				switch class[cidx] {
+				case '\\', '[', ']', '^':
+					buf.WriteByte('\\')
+				}
+
+				buf.WriteByte(class[cidx])
+			}
+
+			buf.WriteString("]")
+			idx += end + 1
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+
+	if depth > 0 {
+		return "", fmt.Errorf("invalid glob '%s': unbalanced '{'", pattern)
+	}
+
+	buf.WriteString("$")
+	return buf.String(), nil
+}
+
+/**
+ *	Matches reports whether the text document identified by uri and languageId is selected.
+ *	Notebook cell filters never match since they require the containing notebook, see NotebookDocumentFilter.Matches.
+ */
+func (s DocumentSelector) Matches(uri DocumentUri, languageId string) bool {
+	for _, item := range s {
+		switch {
+		case item.Opt1 != nil:
+			if *item.Opt1 == languageId {
+				return true
+			}
+		case item.Opt2 != nil && item.Opt2.Opt1 != nil:
+			if item.Opt2.Opt1.Matches(uri, languageId) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (f TextDocumentFilter) Matches(uri DocumentUri, languageId string) bool {
+	if f.Language != "" && f.Language != languageId {
+		return false
+	}
+
+	return matchUri(string(uri), f.Scheme, f.Pattern, false)
+}
+
+func (f NotebookDocumentFilter) Matches(uri DocumentUri, notebookType string) bool {
+	if f.NotebookType != "" && f.NotebookType != notebookType {
+		return false
+	}
+
+	return matchUri(string(uri), f.Scheme, f.Pattern, false)
+}
+
+/**
+ *	Matches reports whether the file or folder identified by uri matches one of the filters.
+ */
+func (o FileOperationRegistrationOptions) Matches(uri string, kind FileOperationPatternKind) bool {
+	for _, filter := range o.Filters {
+		if filter.Matches(uri, kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f FileOperationFilter) Matches(uri string, kind FileOperationPatternKind) bool {
+	if f.Pattern.Matches != nil && *f.Pattern.Matches != kind {
+		return false
+	}
+
+	ignoreCase := f.Pattern.Options != nil && f.Pattern.Options.IgnoreCase
+	return matchUri(uri, f.Scheme, f.Pattern.Glob, ignoreCase)
+}
+
+func (p FileOperationPattern) MatchesPath(path string, kind FileOperationPatternKind) bool {
+	if p.Matches != nil && *p.Matches != kind {
+		return false
+	}
+
+	return MatchGlob(p.Glob, path, p.Options != nil && p.Options.IgnoreCase)
+}
+
+func matchUri(uri string, scheme string, pattern string, ignoreCase bool) bool {
+	if scheme == "" && pattern == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	if scheme != "" && parsed.Scheme != scheme {
+		return false
+	}
+
+	return pattern == "" || MatchGlob(pattern, parsed.Path, ignoreCase)
+}
@@ -0,0 +1,104 @@
+package lsp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		path       string
+		ignoreCase bool
+		want       bool
+	}{
+		{pattern: "*.go", path: "main.go", want: true},
+		{pattern: "*.go", path: "cmd/main.go", want: false},
+		{pattern: "**/*.go", path: "main.go", want: true},
+		{pattern: "**/*.go", path: "/src/cmd/main.go", want: true},
+		{pattern: "src/**/test.go", path: "src/test.go", want: true},
+		{pattern: "src/**/test.go", path: "src/a/b/test.go", want: true},
+		{pattern: "src/**", path: "src/a/b", want: true},
+		{pattern: "src/**", path: "src", want: true},
+		{pattern: "src/**", path: "srcs", want: false},
+		{pattern: "**/node_modules/**", path: "web/node_modules", want: true},
+		{pattern: "file?.txt", path: "file1.txt", want: true},
+		{pattern: "file?.txt", path: "file/.txt", want: false},
+		{pattern: "**/*.{ts,js}", path: "web/app.js", want: true},
+		{pattern: "**/*.{ts,js}", path: "web/app.go", want: false},
+		{pattern: "{src,lib}/{a,b}.go", path: "lib/b.go", want: true},
+		{pattern: "*.{go,mod}", path: "a,b", want: false},
+		{pattern: "file[0-9].txt", path: "file7.txt", want: true},
+		{pattern: "file[0-9].txt", path: "filex.txt", want: false},
+		{pattern: "file[!0-9].txt", path: "filex.txt", want: true},
+		{pattern: "file[!0-9].txt", path: "file7.txt", want: false},
+		{pattern: "a[!x]b", path: "a/b", want: false},
+		{pattern: "[]]", path: "]", want: true},
+		{pattern: "[]a]", path: "a", want: true},
+		{pattern: "[!]]", path: "]", want: false},
+		{pattern: "[!]]", path: "a", want: true},
+		{pattern: "a.b", path: "axb", want: false},
+		{pattern: "*.GO", path: "main.go", want: false},
+		{pattern: "*.GO", path: "main.go", ignoreCase: true, want: true},
+		{pattern: "{a,b", path: "a", want: false},
+		{pattern: "a}", path: "a}", want: false},
+		{pattern: "[a-z", path: "[a-z", want: false},
+	}
+
+	for _, test := range tests {
+		if got := MatchGlob(test.pattern, test.path, test.ignoreCase); got != test.want {
+			t.Errorf("MatchGlob(%q, %q, %t) = %t, want %t", test.pattern, test.path, test.ignoreCase, got, test.want)
+		}
+	}
+}
+
+func TestCompileGlobInvalid(t *testing.T) {
+	for _, pattern := range []string{"{a,b", "a}", "[a-z", "[]"} {
+		if _, err := CompileGlob(pattern, false); err == nil {
+			t.Errorf("CompileGlob(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestCompileGlobCacheBounded(t *testing.T) {
+	for idx := 0; idx < 2*globCacheSize; idx++ {
+		if _, err := CompileGlob(fmt.Sprintf("**/file%d.go", idx), false); err != nil {
+			t.Fatalf("CompileGlob failed: %v", err)
+		}
+	}
+
+	globCacheLock.Lock()
+	size := len(globCache)
+	globCacheLock.Unlock()
+
+	if size > globCacheSize {
+		t.Errorf("glob cache holds %d globs, want at most %d", size, globCacheSize)
+	}
+}
+
+func TestDocumentSelectorMatches(t *testing.T) {
+	lang := "go"
+	selector := DocumentSelector{
+		{Opt1: &lang},
+		{Opt2: &DocumentFilter{Opt1: &TextDocumentFilter{Scheme: "untitled"}}},
+		{Opt2: &DocumentFilter{Opt1: &TextDocumentFilter{Language: "json", Pattern: "**/package.json"}}},
+	}
+
+	tests := []struct {
+		uri        DocumentUri
+		languageId string
+		want       bool
+	}{
+		{"file:///src/main.go", "go", true},
+		{"untitled:Untitled-1", "plaintext", true},
+		{"file:///web/package.json", "json", true},
+		{"file:///web/tsconfig.json", "json", false},
+		{"file:///web/package.json", "jsonc", false},
+	}
+
+	for _, test := range tests {
+		if got := selector.Matches(test.uri, test.languageId); got != test.want {
+			t.Errorf("Matches(%q, %q) = %t, want %t", test.uri, test.languageId, got, test.want)
+		}
+	}
+}
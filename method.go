@@ -3,6 +3,7 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/trwk76/jsonrpc"
@@ -12,18 +13,28 @@ func NewStandardMethodSet[Ctxt any]() *MethodSet[Ctxt] {
 	set := NewMethodSet[Ctxt]()
 
 	addLifecycleMethods(set)
+	addCancelMethods(set)
+	addProgressMethods(set)
+	addClientMethods(set)
+	addWindowMethods(set)
+	addTraceMethods(set)
+	addRegistrationMethods(set)
+	addWorkspaceMethods(set)
+	addConfigurationMethods(set)
+	addWatchMethods(set)
 
 	return set
 }
 
 /**
- *	MethodDirection is an enumeration specifying if a method is meant to be sent from Client to Server or Server to Client.
+ *	MethodDirection is a set of flags specifying if a method is meant to be sent from Client to Server, Server to Client or both.
  */
 type MethodDirection uint8
 
 const (
 	ClientToServer MethodDirection = 1
 	ServerToClient MethodDirection = 2
+	BothDirections MethodDirection = ClientToServer | ServerToClient
 )
 
 /**
@@ -53,6 +64,7 @@ type NotificationDefinition[Ctxt any] interface {
  */
 type MethodSet[Ctxt any] struct {
 	names map[string]MethodDefinition[Ctxt]
+	caps  []CapabilitiesHandler
 }
 
 func NewMethodSet[Ctxt any]() *MethodSet[Ctxt] {
@@ -129,7 +141,13 @@ func NewRequest[Ctxt any, PA any, RE any](method string, dir MethodDirection, pr
 
 type TypedRequestWithPartialHandler[Ctxt any, PA PartialParams, RE any, PR any] func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params PA, partial *PartialResult[PR]) (*RE, error)
 
-func NewRequestWithPartial[Ctxt any, PA PartialParams, RE any, PR any](method string, dir MethodDirection, port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, process TypedRequestWithPartialHandler[Ctxt, PA, RE, PR]) RequestDefinition[Ctxt] {
+/**
+ *	NewRequestWithPartial creates a request whose handler may stream results through a PartialResult.
+ *	Once partial results were sent, the final response is forced to be empty: a result returned by the handler is
+ *	sent as a last partial result when it has the PR type or implements PartialResultConverter, and fails the request otherwise.
+ *	Results implementing PartialResultRemainder keep the fields that cannot be streamed in the final response.
+ */
+func NewRequestWithPartial[Ctxt any, PA PartialParams, RE any, PR any](method string, dir MethodDirection, process TypedRequestWithPartialHandler[Ctxt, PA, RE, PR]) RequestDefinition[Ctxt] {
 	return NewRawRequest(
 		method,
 		dir,
@@ -152,7 +170,40 @@ func NewRequestWithPartial[Ctxt any, PA PartialParams, RE any, PR any](method st
 				}
 			}
 
-			if res, err = process(ctx, srv, port, hdrs, id, par, partial); err != nil {
+			res, err = process(ctx, srv, port, hdrs, id, par, partial)
+
+			if partial != nil {
+				if err == nil && partial.Used() {
+					var rest *RE
+
+					if res != nil {
+						if last, ok := any(*res).(PR); ok {
+							err = partial.Send(last)
+						} else if conv, ok := any(*res).(PartialResultConverter[PR]); ok {
+							err = partial.Send(conv.PartialResult())
+						} else {
+							err = fmt.Errorf("%s: result of type %T cannot be sent after partial results", method, *res)
+						}
+
+						if rem, ok := any(*res).(PartialResultRemainder[RE]); ok {
+							val := rem.PartialResultRemainder()
+							rest = &val
+						}
+					}
+
+					if rest == nil {
+						rest = emptyResult[RE]()
+					}
+
+					res = rest
+				}
+
+				if cerr := partial.Close(); err == nil {
+					err = cerr
+				}
+			}
+
+			if err != nil {
 				return nil, err
 			}
 
@@ -0,0 +1,220 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	NavigationProvider interface answers the go-to family of requests.
+ *	NavigationOptions returns the options of the requests the provider supports; requests without options are neither
+ *	registered nor advertised. Results are either locations or links; links are downgraded to locations for clients
+ *	without link support.
+ */
+type NavigationProvider interface {
+	NavigationOptions() NavigationOptions
+	Declaration(ctx context.Context, params DeclarationParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error)
+	Definition(ctx context.Context, params DefinitionParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error)
+	TypeDefinition(ctx context.Context, params TypeDefinitionParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error)
+	Implementation(ctx context.Context, params ImplementationParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error)
+}
+
+type NavigationOptions struct {
+	Declaration    *DeclarationRegistrationOptions
+	Definition     *DefinitionOptions
+	TypeDefinition *TypeDefinitionRegistrationOptions
+	Implementation *ImplementationRegistrationOptions
+}
+
+/**
+ *	AddNavigationMethods adds the navigation methods supported by provider to the set and advertises them.
+ */
+func AddNavigationMethods[Ctxt any](set *MethodSet[Ctxt], provider NavigationProvider) {
+	opts := provider.NavigationOptions()
+
+	if opts.Declaration != nil {
+		addNavigationMethod(set, Method_Declaration, func(caps *TextDocumentClientCapabilities) *NavigationClientCapabilities { return caps.Declaration }, provider.Declaration)
+	}
+
+	if opts.Definition != nil {
+		addNavigationMethod(set, Method_Definition, func(caps *TextDocumentClientCapabilities) *NavigationClientCapabilities { return caps.Definition }, provider.Definition)
+	}
+
+	if opts.TypeDefinition != nil {
+		addNavigationMethod(set, Method_TypeDefinition, func(caps *TextDocumentClientCapabilities) *NavigationClientCapabilities { return caps.TypeDefinition }, provider.TypeDefinition)
+	}
+
+	if opts.Implementation != nil {
+		addNavigationMethod(set, Method_Implementation, func(caps *TextDocumentClientCapabilities) *NavigationClientCapabilities { return caps.Implementation }, provider.Implementation)
+	}
+
+	set.AddCapabilities(func(caps *ServerCapabilities) {
+		if caps.DeclarationProvider == nil {
+			caps.DeclarationProvider = opts.Declaration
+		}
+
+		if caps.DefinitionProvider == nil {
+			caps.DefinitionProvider = opts.Definition
+		}
+
+		if caps.TypeDefinitionProvider == nil {
+			caps.TypeDefinitionProvider = opts.TypeDefinition
+		}
+
+		if caps.ImplementationProvider == nil {
+			caps.ImplementationProvider = opts.Implementation
+		}
+	})
+}
+
+func addNavigationMethod[Ctxt any, PA PartialParams](set *MethodSet[Ctxt], method string, clientCaps func(caps *TextDocumentClientCapabilities) *NavigationClientCapabilities, process func(ctx context.Context, params PA, partial *PartialResult[NavigationResult]) (*NavigationResult, error)) {
+	set.Add(NewRequestWithPartial(method, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params PA, partial *PartialResult[NavigationResult]) (*NavigationResult, error) {
+		caps := srv.ClientInfo().Capabilites
+		links := caps.TextDocument != nil && clientCaps(caps.TextDocument) != nil && clientCaps(caps.TextDocument).LinkSupport
+
+		if partial != nil && !links {
+			partial.adapt = DowngradeNavigationResult
+		}
+
+		res, err := process(ctx, params, partial)
+		if err != nil || res == nil {
+			return nil, err
+		}
+
+		if !links {
+			downgraded := DowngradeNavigationResult(*res)
+			res = &downgraded
+		}
+
+		return res, nil
+	}))
+}
+
+func NewNavigationLocations(locations []Location) NavigationResult {
+	return NavigationResult{Opt1: &locations}
+}
+
+func NewNavigationLinks(links []LocationLink) NavigationResult {
+	return NavigationResult{Opt2: &links}
+}
+
+/**
+ *	DowngradeNavigationResult converts links to the Location of their target selection range; locations are returned as is.
+ */
+func DowngradeNavigationResult(res NavigationResult) NavigationResult {
+	if res.Opt2 == nil {
+		return res
+	}
+
+	locations := make([]Location, len(*res.Opt2))
+
+	for idx, link := range *res.Opt2 {
+		locations[idx] = link.Location()
+	}
+
+	return NewNavigationLocations(locations)
+}
+
+func (l LocationLink) Location() Location {
+	return Location{
+		Uri:   l.TargetUri,
+		Range: l.TargetSelectionRange,
+	}
+}
+
+// Supporting types
+const (
+	Method_Declaration    string = "textDocument/declaration"
+	Method_Definition     string = "textDocument/definition"
+	Method_TypeDefinition string = "textDocument/typeDefinition"
+	Method_Implementation string = "textDocument/implementation"
+)
+
+type Location struct {
+	Uri   DocumentUri `json:"uri"`
+	Range Range       `json:"range"`
+}
+
+type LocationLink struct {
+	OriginSelectionRange *Range      `json:"originSelectionRange,omitempty"`
+	TargetUri            DocumentUri `json:"targetUri"`
+	TargetRange          Range       `json:"targetRange"`
+	TargetSelectionRange Range       `json:"targetSelectionRange"`
+}
+
+/**
+ *	NavigationResult is the result of the navigation requests: locations, or links when the client supports them.
+ */
+type NavigationResult = Choice2[[]Location, []LocationLink]
+
+/**
+ *	NavigationClientCapabilities struct holds the client capabilities shared by the navigation requests.
+ */
+type NavigationClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	LinkSupport         bool `json:"linkSupport,omitempty"`
+}
+
+type DeclarationClientCapabilities = NavigationClientCapabilities
+type DefinitionClientCapabilities = NavigationClientCapabilities
+type TypeDefinitionClientCapabilities = NavigationClientCapabilities
+type ImplementationClientCapabilities = NavigationClientCapabilities
+
+type DeclarationOptions struct {
+	WorkDoneProgressOptions
+}
+
+type DeclarationRegistrationOptions struct {
+	DeclarationOptions
+	TextDocumentRegistrationOptions
+	StaticRegistrationOptions
+}
+
+type DefinitionOptions struct {
+	WorkDoneProgressOptions
+}
+
+type TypeDefinitionOptions struct {
+	WorkDoneProgressOptions
+}
+
+type TypeDefinitionRegistrationOptions struct {
+	TypeDefinitionOptions
+	TextDocumentRegistrationOptions
+	StaticRegistrationOptions
+}
+
+type ImplementationOptions struct {
+	WorkDoneProgressOptions
+}
+
+type ImplementationRegistrationOptions struct {
+	ImplementationOptions
+	TextDocumentRegistrationOptions
+	StaticRegistrationOptions
+}
+
+type DeclarationParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+type DefinitionParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+type TypeDefinitionParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+type ImplementationParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
@@ -0,0 +1,104 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type testNavigationProvider struct {
+	streamed *NavigationResult
+	result   NavigationResult
+}
+
+func (p testNavigationProvider) NavigationOptions() NavigationOptions {
+	return NavigationOptions{Definition: &DefinitionOptions{}}
+}
+
+func (p testNavigationProvider) Declaration(ctx context.Context, params DeclarationParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error) {
+	return nil, nil
+}
+
+func (p testNavigationProvider) Definition(ctx context.Context, params DefinitionParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error) {
+	if partial != nil && p.streamed != nil {
+		if err := partial.Send(*p.streamed); err != nil {
+			return nil, err
+		}
+	}
+
+	res := p.result
+	return &res, nil
+}
+
+func (p testNavigationProvider) TypeDefinition(ctx context.Context, params TypeDefinitionParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error) {
+	return nil, nil
+}
+
+func (p testNavigationProvider) Implementation(ctx context.Context, params ImplementationParams, partial *PartialResult[NavigationResult]) (*NavigationResult, error) {
+	return nil, nil
+}
+
+func TestNavigationLinkDowngrade(t *testing.T) {
+	links := NewNavigationLinks([]LocationLink{{
+		TargetUri:            "file:///b.go",
+		TargetRange:          Range{Start: Position{Line: 1}, End: Position{Line: 5}},
+		TargetSelectionRange: Range{Start: Position{Line: 1, Character: 5}, End: Position{Line: 1, Character: 8}},
+	}})
+	locations := NewNavigationLocations([]Location{{Uri: "file:///c.go", Range: Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 3}}}})
+
+	const (
+		linkSupport = `{"textDocument":{"definition":{"linkSupport":true}}}`
+		linkJSON    = `[{"targetUri":"file:///b.go","targetRange":{"start":{"line":1,"character":0},"end":{"line":5,"character":0}},"targetSelectionRange":{"start":{"line":1,"character":5},"end":{"line":1,"character":8}}}]`
+		linkAsLoc   = `[{"uri":"file:///b.go","range":{"start":{"line":1,"character":5},"end":{"line":1,"character":8}}}]`
+		locJSON     = `[{"uri":"file:///c.go","range":{"start":{"line":2,"character":0},"end":{"line":2,"character":3}}}]`
+	)
+
+	tests := []struct {
+		capabilities string
+		streamed     *NavigationResult
+		result       NavigationResult
+		token        bool
+		response     string
+		sent         []string
+	}{
+		{linkSupport, nil, links, false, linkJSON, nil},
+		{`{}`, nil, links, false, linkAsLoc, nil},
+		// Link support is per request, support for declaration does not apply to definition.
+		{`{"textDocument":{"declaration":{"linkSupport":true}}}`, nil, links, false, linkAsLoc, nil},
+		{linkSupport, nil, locations, false, locJSON, nil},
+		{`{}`, nil, locations, false, locJSON, nil},
+		// Once results were streamed, the final response is the empty null result.
+		{linkSupport, &links, locations, true, `null`, []string{linkJSON, locJSON}},
+		{`{}`, &links, locations, true, `null`, []string{linkAsLoc, locJSON}},
+	}
+
+	for i, test := range tests {
+		rec := recordProgress(t)
+		srv := NewServer[struct{}]()
+		AddNavigationMethods(srv.Methods(), testNavigationProvider{streamed: test.streamed, result: test.result})
+		res := initializeTestServer(t, srv, test.capabilities)
+
+		if res.Capabilities.DefinitionProvider == nil || res.Capabilities.DeclarationProvider != nil {
+			t.Errorf("case %d: advertised definition %v and declaration %v, want only definition", i, res.Capabilities.DefinitionProvider, res.Capabilities.DeclarationProvider)
+		}
+
+		params := `{"textDocument":{"uri":"file:///a.go"},"position":{"line":0,"character":0}`
+
+		if test.token {
+			params += `,"partialResultToken":"p"`
+		}
+
+		data, err := srv.processRequest(context.Background(), nil, nil, 2, Method_Definition, json.RawMessage(params+`}`))
+		if err != nil {
+			t.Fatalf("case %d: definition failed: %v", i, err)
+		}
+
+		if string(data) != test.response {
+			t.Errorf("case %d: response %s, want %s", i, data, test.response)
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.sent) {
+			t.Errorf("case %d: sent %v, want %v", i, got, test.sent)
+		}
+	}
+}
@@ -1,11 +1,33 @@
 package lsp
 
-import "github.com/trwk76/jsonrpc"
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	PartialResult struct streams partial results of a request to the client through $/progress.
+ *	When PR is a slice type, results can be batched so that several Send calls produce a single notification.
+ */
 type PartialResult[PR any] struct {
-	port  jsonrpc.Port
-	hdrs  *jsonrpc.HeaderSet
-	token ProgressToken
+	lock    sync.Mutex
+	port    jsonrpc.Port
+	hdrs    *jsonrpc.HeaderSet
+	token   ProgressToken
+	size    int
+	delay   time.Duration
+	pending *PR
+	count   int
+	timer   *time.Timer
+	err     error
+	used    bool
+	closed  bool
+	hold    bool
+	adapt   func(PR) PR
 }
 
 // Supporting types
@@ -13,6 +35,34 @@ type PartialParams interface {
 	PartialToken() ProgressToken
 }
 
+type PartialResultParams struct {
+	PartialResultToken *ProgressToken `json:"partialResultToken,omitempty"`
+}
+
+func (p PartialResultParams) PartialToken() ProgressToken {
+	if p.PartialResultToken == nil {
+		return nil
+	}
+
+	return *p.PartialResultToken
+}
+
+/**
+ *	PartialResultConverter may be implemented by the result type of a request with partial results
+ *	to convert a result returned after partial results were sent into a last partial result.
+ */
+type PartialResultConverter[PR any] interface {
+	PartialResult() PR
+}
+
+/**
+ *	PartialResultRemainder may be implemented by the result type of a request with partial results
+ *	to answer with the fields that are not part of the partial results, instead of an empty value, once they were sent.
+ */
+type PartialResultRemainder[RE any] interface {
+	PartialResultRemainder() RE
+}
+
 func NewPartialResult[PR any](port jsonrpc.Port, token ProgressToken, hdrs *jsonrpc.HeaderSet) *PartialResult[PR] {
 	return &PartialResult[PR]{
 		port:  port,
@@ -21,13 +71,158 @@ func NewPartialResult[PR any](port jsonrpc.Port, token ProgressToken, hdrs *json
 	}
 }
 
+/**
+ *	SetBatching makes Send accumulate results until size items are pending or delay has elapsed since the first pending one.
+ *	A zero size or delay disables the corresponding trigger; batching only applies when PR is a slice type.
+ */
+func (r *PartialResult[PR]) SetBatching(size int, delay time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if typeOf[PR]().Kind() != reflect.Slice {
+		return
+	}
+
+	r.size = size
+	r.delay = delay
+}
+
+/**
+ *	Used reports whether any result was given to Send, in which case the final response of the request must be empty.
+ */
+func (r *PartialResult[PR]) Used() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.used
+}
+
 func (r *PartialResult[PR]) Send(result PR) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("partial result is closed")
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	r.used = true
+
+	if r.size <= 0 && r.delay <= 0 && !r.hold {
+		return r.send(result)
+	}
+
+	if r.pending == nil {
+		r.pending = &result
+	} else {
+		merged := reflect.AppendSlice(reflect.ValueOf(*r.pending), reflect.ValueOf(result)).Interface().(PR)
+		r.pending = &merged
+	}
+
+	r.count += reflect.ValueOf(result).Len()
+
+	if r.hold {
+		return nil
+	}
+
+	if r.size > 0 && r.count >= r.size {
+		return r.flush()
+	}
+
+	if r.delay > 0 && r.timer == nil {
+		r.timer = time.AfterFunc(r.delay, func() {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.timer = nil
+
+			if err := r.flush(); err != nil && r.err == nil {
+				r.err = err
+			}
+		})
+	}
+
+	return nil
+}
+
+/**
+ *	Flush sends the pending batched results, if any. Results held until the request completes are not sent.
+ */
+func (r *PartialResult[PR]) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil || r.hold {
+		return r.err
+	}
+
+	return r.flush()
+}
+
+/**
+ *	Close flushes the pending results; further Send calls fail.
+ */
+func (r *PartialResult[PR]) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.flush()
+}
+
+func (r *PartialResult[PR]) flush() error {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	if r.pending == nil {
+		return nil
+	}
+
+	result := *r.pending
+	r.pending = nil
+	r.count = 0
+
+	return r.send(result)
+}
+
+func (r *PartialResult[PR]) send(result PR) error {
 	var pa *ProgressParams
 	var err error
 
+	if r.adapt != nil {
+		result = r.adapt(result)
+	}
+
 	if pa, err = newProgressParams(r.token, result); err != nil {
 		return err
 	}
 
-	return jsonrpc.SendNotification(r.port, r.hdrs, ProgressMethod, *pa)
+	return sendProgress(r.port, r.hdrs, *pa)
+}
+
+/**
+ *	emptyResult returns the empty value of RE to answer a request whose results were all sent as partial results.
+ */
+func emptyResult[RE any]() *RE {
+	var res RE
+
+	if val := reflect.ValueOf(&res).Elem(); val.Kind() == reflect.Slice {
+		val.Set(reflect.MakeSlice(val.Type(), 0, 0))
+	}
+
+	return &res
 }
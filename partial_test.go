@@ -0,0 +1,204 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+type progressRecorder struct {
+	lock   sync.Mutex
+	values []string
+}
+
+func recordProgress(t *testing.T) *progressRecorder {
+	rec := &progressRecorder{}
+	prev := sendProgress
+
+	sendProgress = func(port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params ProgressParams) error {
+		rec.lock.Lock()
+		defer rec.lock.Unlock()
+
+		rec.values = append(rec.values, string(params.Value))
+		return nil
+	}
+
+	t.Cleanup(func() { sendProgress = prev })
+	return rec
+}
+
+func (r *progressRecorder) sent() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.values...)
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestPartialResultBatching(t *testing.T) {
+	tests := []struct {
+		size  int
+		delay time.Duration
+		sends [][]int
+		// before lists the notifications sent before Close, after those sent once it returned.
+		before []string
+		after  []string
+	}{
+		{0, 0, [][]int{{1}, {2}}, []string{`[1]`, `[2]`}, []string{`[1]`, `[2]`}},
+		{2, 0, [][]int{{1}, {2}, {3}}, []string{`[1,2]`}, []string{`[1,2]`, `[3]`}},
+		{3, 0, [][]int{{1, 2, 3, 4}}, []string{`[1,2,3,4]`}, []string{`[1,2,3,4]`}},
+		{0, time.Hour, [][]int{{1}, {2}}, nil, []string{`[1,2]`}},
+	}
+
+	for _, test := range tests {
+		rec := recordProgress(t)
+		partial := NewPartialResult[[]int](nil, "token", nil)
+		partial.SetBatching(test.size, test.delay)
+
+		for _, items := range test.sends {
+			if err := partial.Send(items); err != nil {
+				t.Fatalf("size %d delay %v: Send failed: %v", test.size, test.delay, err)
+			}
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.before) {
+			t.Errorf("size %d delay %v: sent %v before Close, want %v", test.size, test.delay, got, test.before)
+		}
+
+		if err := partial.Close(); err != nil {
+			t.Fatalf("size %d delay %v: Close failed: %v", test.size, test.delay, err)
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.after) {
+			t.Errorf("size %d delay %v: sent %v after Close, want %v", test.size, test.delay, got, test.after)
+		}
+
+		if err := partial.Send([]int{5}); err == nil {
+			t.Errorf("size %d delay %v: Send after Close succeeded", test.size, test.delay)
+		}
+	}
+}
+
+func TestPartialResultBatchingDelay(t *testing.T) {
+	rec := recordProgress(t)
+	partial := NewPartialResult[[]int](nil, "token", nil)
+	partial.SetBatching(0, 10*time.Millisecond)
+
+	partial.Send([]int{1})
+	partial.Send([]int{2})
+
+	for deadline := time.Now().Add(time.Second); len(rec.sent()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got, want := rec.sent(), []string{`[1,2]`}; !equalStrings(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+
+	if err := partial.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+type partialTestParams struct {
+	PartialResultParams
+}
+
+type partialTestResult struct {
+	Items []int  `json:"items"`
+	Label string `json:"label"`
+}
+
+func TestRequestWithPartialFinalResult(t *testing.T) {
+	tests := []struct {
+		token    string
+		partials [][]int
+		result   *[]int
+		// whole answers with a result that has no partial result form.
+		whole    bool
+		sent     []string
+		response string
+		fails    bool
+	}{
+		// Without a token the result is the response.
+		{``, nil, &[]int{1}, false, nil, `[1]`, false},
+		{``, [][]int{{1}}, &[]int{2}, false, nil, `[2]`, false},
+		// Without partial results sent, the result is the response.
+		{`"partialResultToken":"p"`, nil, &[]int{1}, false, nil, `[1]`, false},
+		// Once partial results were sent, the final response is empty.
+		{`"partialResultToken":"p"`, [][]int{{1}}, nil, false, []string{`[1]`}, `[]`, false},
+		{`"partialResultToken":"p"`, [][]int{{1}}, &[]int{}, false, []string{`[1]`, `[]`}, `[]`, false},
+		{`"partialResultToken":"p"`, [][]int{{1}}, &[]int{2}, false, []string{`[1]`, `[2]`}, `[]`, false},
+		{`"partialResultToken":"p"`, nil, nil, true, nil, `{"items":null,"label":"whole"}`, false},
+		{`"partialResultToken":"p"`, [][]int{{1}}, nil, true, []string{`[1]`}, ``, true},
+	}
+
+	for i, test := range tests {
+		rec := recordProgress(t)
+		srv := NewServer[struct{}]()
+		srv.setState(ServerState_Initialized)
+
+		srv.Methods().Add(NewRequestWithPartial("test/list", ClientToServer, func(ctx context.Context, srv *Server[struct{}], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params partialTestParams, partial *PartialResult[[]int]) (*[]int, error) {
+			if partial != nil {
+				for _, items := range test.partials {
+					if err := partial.Send(items); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			return test.result, nil
+		}))
+
+		srv.Methods().Add(NewRequestWithPartial("test/whole", ClientToServer, func(ctx context.Context, srv *Server[struct{}], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params partialTestParams, partial *PartialResult[[]int]) (*partialTestResult, error) {
+			if partial != nil {
+				for _, items := range test.partials {
+					if err := partial.Send(items); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			return &partialTestResult{Label: "whole"}, nil
+		}))
+
+		method := "test/list"
+
+		if test.whole {
+			method = "test/whole"
+		}
+
+		res, err := srv.processRequest(context.Background(), nil, nil, 1, method, json.RawMessage(`{`+test.token+`}`))
+
+		if test.fails {
+			if err == nil {
+				t.Errorf("case %d: request succeeded with %s, want an error", i, res)
+			}
+		} else if err != nil {
+			t.Errorf("case %d: request failed: %v", i, err)
+		} else if string(res) != test.response {
+			t.Errorf("case %d: response %s, want %s", i, res, test.response)
+		}
+
+		if got := rec.sent(); !equalStrings(got, test.sent) {
+			t.Errorf("case %d: sent %v, want %v", i, got, test.sent)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package lsp
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/trwk76/jsonrpc"
+)
 
 // Supporting types
 const (
@@ -30,6 +34,13 @@ func newProgressParams[VA any](token ProgressToken, value VA) (*ProgressParams,
 	}, nil
 }
 
+/**
+ *	sendProgress sends a $/progress notification; tests replace it to observe partial results and work done progress.
+ */
+var sendProgress = func(port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params ProgressParams) error {
+	return jsonrpc.SendNotification(port, hdrs, ProgressMethod, params)
+}
+
 type WorkDoneProgressCreateParams struct {
 	Token ProgressToken `json:"token"`
 }
@@ -0,0 +1,288 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Supporting types
+const (
+	RegisterCapabilityMethod   string = "client/registerCapability"
+	UnregisterCapabilityMethod string = "client/unregisterCapability"
+)
+
+type Registration struct {
+	Id              string      `json:"id"`
+	Method          string      `json:"method"`
+	RegisterOptions interface{} `json:"registerOptions,omitempty"`
+}
+
+type RegistrationParams struct {
+	Registrations []Registration `json:"registrations"`
+}
+
+type Unregistration struct {
+	Id     string `json:"id"`
+	Method string `json:"method"`
+}
+
+type StaticRegistrationOptions struct {
+	Id *string `json:"id,omitempty"`
+}
+
+type UnregistrationParams struct {
+	// The misspelling is part of the protocol.
+	Unregisterations []Unregistration `json:"unregisterations"`
+}
+
+func addRegistrationMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewServerToClientRequest[Ctxt, RegistrationParams, Void](RegisterCapabilityMethod, nil))
+	set.Add(NewServerToClientRequest[Ctxt, UnregistrationParams, Void](UnregisterCapabilityMethod, nil))
+}
+
+/**
+ *	RegisterCapability registers method with the client and returns the id of the registration.
+ *	When the client does not support dynamic registration for method, which is always the case before initialize, the
+ *	registration falls back to a static one merged into the capabilities advertised in the InitializeResult.
+ *	Static registrations can only be made before initialize: once initialized, registering a method the client cannot
+ *	register dynamically fails with ErrStaticRegistrationClosed, and methods without a static counterpart always fail
+ *	with ErrClientUnsupported.
+ */
+func (s *Server[Ctxt]) RegisterCapability(ctx context.Context, method string, options interface{}) (string, error) {
+	if !s.SupportsDynamicRegistration(method) {
+		return s.registerStatic(method, options)
+	}
+
+	reg := s.newRegistration(method, options)
+
+	if _, err := Call[Ctxt, RegistrationParams, Void](ctx, s, RegisterCapabilityMethod, RegistrationParams{Registrations: []Registration{reg}}); err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	s.registrations[reg.Id] = reg
+	s.lock.Unlock()
+
+	return reg.Id, nil
+}
+
+/**
+ *	UnregisterCapability removes a registration previously made with RegisterCapability.
+ */
+func (s *Server[Ctxt]) UnregisterCapability(ctx context.Context, id string) error {
+	s.lock.Lock()
+	reg, ok := s.registrations[id]
+
+	if !ok {
+		for idx, static := range s.staticRegs {
+			if static.Id != id {
+				continue
+			}
+
+			if s.state != ServerState_Uninitialized {
+				s.lock.Unlock()
+				return fmt.Errorf("%w: removal of static registration '%s' once initialized", ErrClientUnsupported, id)
+			}
+
+			s.staticRegs = append(s.staticRegs[:idx], s.staticRegs[idx+1:]...)
+			s.lock.Unlock()
+			return nil
+		}
+	}
+
+	s.lock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("registration '%s' is not active", id)
+	}
+
+	params := UnregistrationParams{
+		Unregisterations: []Unregistration{{Id: reg.Id, Method: reg.Method}},
+	}
+
+	if _, err := Call[Ctxt, UnregistrationParams, Void](ctx, s, UnregisterCapabilityMethod, params); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	delete(s.registrations, id)
+	s.lock.Unlock()
+
+	return nil
+}
+
+/**
+ *	Registrations returns the active dynamic registrations.
+ */
+func (s *Server[Ctxt]) Registrations() []Registration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := make([]Registration, 0, len(s.registrations))
+
+	for _, reg := range s.registrations {
+		res = append(res, reg)
+	}
+
+	return res
+}
+
+/**
+ *	StaticRegistrations returns the registrations advertised statically because the client could not register them dynamically.
+ */
+func (s *Server[Ctxt]) StaticRegistrations() []Registration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]Registration(nil), s.staticRegs...)
+}
+
+/**
+ *	SupportsDynamicRegistration reports whether the client accepts dynamic registration of method.
+ */
+func (s *Server[Ctxt]) SupportsDynamicRegistration(method string) bool {
+	return supportsDynamicRegistration(s.ClientInfo().Capabilites, method)
+}
+
+func supportsDynamicRegistration(caps ClientCapabilities, method string) bool {
+	switch method {
+	case Method_DidOpenTextDocument, Method_DidChangeTextDocument, Method_WillSaveTextDocument, Method_DidSaveTextDocument, Method_DidCloseTextDocument, Method_WillSaveWaitUntil:
+		return caps.TextDocument != nil && caps.TextDocument.Synchronization != nil && caps.TextDocument.Synchronization.DynamicRegistration
+	case Method_Completion:
+		return caps.TextDocument != nil && caps.TextDocument.Completion != nil && caps.TextDocument.Completion.DynamicRegistration
+	case Method_Hover:
+		return caps.TextDocument != nil && caps.TextDocument.Hover != nil && caps.TextDocument.Hover.DynamicRegistration
+	case Method_SignatureHelp:
+		return caps.TextDocument != nil && caps.TextDocument.SignatureHelp != nil && caps.TextDocument.SignatureHelp.DynamicRegistration
+	case Method_Declaration:
+		return caps.TextDocument != nil && caps.TextDocument.Declaration != nil && caps.TextDocument.Declaration.DynamicRegistration
+	case Method_Definition:
+		return caps.TextDocument != nil && caps.TextDocument.Definition != nil && caps.TextDocument.Definition.DynamicRegistration
+	case Method_TypeDefinition:
+		return caps.TextDocument != nil && caps.TextDocument.TypeDefinition != nil && caps.TextDocument.TypeDefinition.DynamicRegistration
+	case Method_Implementation:
+		return caps.TextDocument != nil && caps.TextDocument.Implementation != nil && caps.TextDocument.Implementation.DynamicRegistration
+	case DidChangeWatchedFilesMethod:
+		return caps.Workspace != nil && caps.Workspace.DidChangeWatchedFiles != nil && caps.Workspace.DidChangeWatchedFiles.DynamicRegistration
+	case DidChangeConfigurationMethod:
+		return caps.Workspace != nil && caps.Workspace.DidChangeConfiguration != nil && caps.Workspace.DidChangeConfiguration.DynamicRegistration
+	}
+
+	return false
+}
+
+func (s *Server[Ctxt]) newRegistration(method string, options interface{}) Registration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.registrationSeq++
+
+	return Registration{
+		Id:              fmt.Sprintf("%s#%d", method, s.registrationSeq),
+		Method:          method,
+		RegisterOptions: options,
+	}
+}
+
+func (s *Server[Ctxt]) registerStatic(method string, options interface{}) (string, error) {
+	// Applying to scratch capabilities validates that method has a static counterpart and options fit it.
+	if err := applyStaticRegistration(&ServerCapabilities{}, method, options); err != nil {
+		return "", err
+	}
+
+	reg := s.newRegistration(method, options)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.state != ServerState_Uninitialized {
+		return "", fmt.Errorf("%w: client does not support dynamic registration of %s", ErrStaticRegistrationClosed, method)
+	}
+
+	s.staticRegs = append(s.staticRegs, reg)
+	return reg.Id, nil
+}
+
+func (s *Server[Ctxt]) applyStaticRegistrations(caps *ServerCapabilities) {
+	for _, reg := range s.StaticRegistrations() {
+		// Registrations were validated when recorded.
+		_ = applyStaticRegistration(caps, reg.Method, reg.RegisterOptions)
+	}
+}
+
+/**
+ *	applyStaticRegistration merges the registration options of method into the matching field of caps.
+ */
+func applyStaticRegistration(caps *ServerCapabilities, method string, options interface{}) error {
+	var sync TextDocumentSyncOptions
+	var err error
+
+	if caps.TextDocumentSync != nil {
+		sync = *caps.TextDocumentSync
+	}
+
+	switch method {
+	case Method_DidOpenTextDocument, Method_DidCloseTextDocument:
+		sync.OpenClose = true
+	case Method_DidChangeTextDocument:
+		var opts TextDocumentChangeRegistrationOptions
+
+		if err = convertOptions(options, &opts); err == nil {
+			sync.Change = opts.SyncKind
+		}
+	case Method_WillSaveTextDocument:
+		sync.WillSave = true
+	case Method_WillSaveWaitUntil:
+		sync.WillSaveWaitUntil = true
+	case Method_DidSaveTextDocument:
+		var opts TextDocumentSaveRegistrationOptions
+
+		if err = convertOptions(options, &opts); err == nil {
+			sync.Save = &opts.SaveOptions
+		}
+	case Method_Completion:
+		err = convertOptions(options, &caps.CompletionProvider)
+	case Method_Hover:
+		err = convertOptions(options, &caps.HoverProvider)
+	case Method_SignatureHelp:
+		err = convertOptions(options, &caps.SignatureHelpProvider)
+	case Method_Declaration:
+		err = convertOptions(options, &caps.DeclarationProvider)
+	case Method_Definition:
+		err = convertOptions(options, &caps.DefinitionProvider)
+	case Method_TypeDefinition:
+		err = convertOptions(options, &caps.TypeDefinitionProvider)
+	case Method_Implementation:
+		err = convertOptions(options, &caps.ImplementationProvider)
+	default:
+		return fmt.Errorf("%w: dynamic registration of %s", ErrClientUnsupported, method)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid registration options for %s: %w", method, err)
+	}
+
+	if sync != (TextDocumentSyncOptions{}) {
+		caps.TextDocumentSync = &sync
+	}
+
+	return nil
+}
+
+/**
+ *	convertOptions converts registration options to the type of the matching static capability through their JSON encoding.
+ */
+func convertOptions[T any](options interface{}, dst *T) error {
+	if options == nil {
+		// No options still enables the capability.
+		options = struct{}{}
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
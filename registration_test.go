@@ -0,0 +1,93 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterCapability(t *testing.T) {
+	triggers := []string{"."}
+
+	tests := []struct {
+		method  string
+		options interface{}
+		// initialized registers once initialized with a client sending capabilities, before initialize otherwise.
+		initialized  bool
+		capabilities string
+		want         error
+		advertised   string
+	}{
+		{Method_Hover, nil, false, `{}`, nil, `"hoverProvider":{}`},
+		{Method_Completion, CompletionOptions{TriggerCharacters: &triggers}, false, `{}`, nil, `"completionProvider":{"triggerCharacters":["."]}`},
+		{Method_DidOpenTextDocument, nil, false, `{}`, nil, `"textDocumentSync":{"openClose":true}`},
+		{"test/unknown", nil, false, `{}`, ErrClientUnsupported, ``},
+		// Once initialized, a client supporting dynamic registration is called, which fails without a connection.
+		{Method_Hover, nil, true, `{"textDocument":{"hover":{"dynamicRegistration":true}}}`, ErrNotServing, ``},
+		{Method_Hover, nil, true, `{}`, ErrStaticRegistrationClosed, ``},
+		{Method_Hover, nil, true, `{"textDocument":{"completion":{"dynamicRegistration":true}}}`, ErrStaticRegistrationClosed, ``},
+		{"test/unknown", nil, true, `{}`, ErrClientUnsupported, ``},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		var res InitializeResult
+		var err error
+
+		if test.initialized {
+			initializeTestServer(t, srv, test.capabilities)
+			_, err = srv.RegisterCapability(context.Background(), test.method, test.options)
+		} else {
+			_, err = srv.RegisterCapability(context.Background(), test.method, test.options)
+			res = initializeTestServer(t, srv, test.capabilities)
+		}
+
+		if test.want == nil && err != nil || test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s initialized %v: got error %v, want %v", test.method, test.initialized, err, test.want)
+		}
+
+		if test.advertised == "" {
+			continue
+		}
+
+		data, _ := json.Marshal(res.Capabilities)
+
+		if !strings.Contains(string(data), test.advertised) {
+			t.Errorf("%s: capabilities %s do not advertise %s", test.method, data, test.advertised)
+		}
+	}
+}
+
+func TestUnregisterStaticCapability(t *testing.T) {
+	srv := NewServer[struct{}]()
+
+	hover, err := srv.RegisterCapability(context.Background(), Method_Hover, nil)
+	if err != nil {
+		t.Fatalf("hover registration failed: %v", err)
+	}
+
+	completion, err := srv.RegisterCapability(context.Background(), Method_Completion, nil)
+	if err != nil {
+		t.Fatalf("completion registration failed: %v", err)
+	}
+
+	if err = srv.UnregisterCapability(context.Background(), hover); err != nil {
+		t.Fatalf("unregistration before initialize failed: %v", err)
+	}
+
+	if regs := srv.StaticRegistrations(); len(regs) != 1 || regs[0].Id != completion {
+		t.Errorf("static registrations %v, want only %s", regs, completion)
+	}
+
+	res := initializeTestServer(t, srv, `{}`)
+
+	if res.Capabilities.HoverProvider != nil || res.Capabilities.CompletionProvider == nil {
+		t.Errorf("advertised hover %v and completion %v, want only completion", res.Capabilities.HoverProvider, res.Capabilities.CompletionProvider)
+	}
+
+	if err = srv.UnregisterCapability(context.Background(), completion); !errors.Is(err, ErrClientUnsupported) {
+		t.Errorf("unregistration once initialized: got error %v, want %v", err, ErrClientUnsupported)
+	}
+}
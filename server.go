@@ -1,7 +1,11 @@
 package lsp
 
 import (
+	"context"
+	"io"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/trwk76/jsonrpc"
 )
@@ -9,25 +13,88 @@ import (
 type EventHandler[Ctxt any] func(srv *Server[Ctxt])
 
 type Server[Ctxt any] struct {
-	lock          sync.Mutex
-	client        *jsonrpc.Client
-	server        *jsonrpc.Server
-	ctxt          Ctxt
-	state         ServerState
-	clientInfo    ClientInfo
-	methods       *MethodSet[Ctxt]
-	exitCode      int
-	OnInitialized EventHandler[Ctxt]
-	OnShutdown    EventHandler[Ctxt]
+	lock             sync.Mutex
+	client           *jsonrpc.Client
+	server           *jsonrpc.Server
+	port             jsonrpc.Port
+	ctxt             Ctxt
+	state            ServerState
+	clientInfo       ClientInfo
+	methods          *MethodSet[Ctxt]
+	exitCode         int
+	requests         map[string]context.CancelFunc
+	progress         map[string]context.CancelFunc
+	posEncoding      PositionEncodingKind
+	trace            InitialTraceValue
+	registrations    map[string]Registration
+	registrationSeq  uint
+	staticRegs       []Registration
+	folders          []WorkspaceFolder
+	config           map[string]configEntry
+	configGen        map[string]uint64
+	configSubs       map[string][]ConfigurationEventHandler[Ctxt]
+	watchers         []localWatcher
+	fileEvents       []FileEvent
+	fileTimer        *time.Timer
+	ServerInfo       *ProgramInfo
+	Capabilities     ServerCapabilities
+	PositionEncoding PositionEncodingKind
+	OnInitialized    EventHandler[Ctxt]
+	OnShutdown       EventHandler[Ctxt]
+
+	OnWorkspaceFoldersChanged WorkspaceFoldersEventHandler[Ctxt]
+	OnFilesChanged            FilesChangedEventHandler[Ctxt]
+	FilesChangedDelay         time.Duration
 }
 
 func NewServer[Ctxt any]() *Server[Ctxt] {
 	return &Server[Ctxt]{
-		state:   ServerState_Uninitialized,
-		methods: NewStandardMethodSet[Ctxt](),
+		state:            ServerState_Uninitialized,
+		methods:          NewStandardMethodSet[Ctxt](),
+		requests:         make(map[string]context.CancelFunc),
+		progress:         make(map[string]context.CancelFunc),
+		posEncoding:      PositionEncodingKind_UTF16,
+		trace:            InitialTraceValue_Off,
+		registrations:    make(map[string]Registration),
+		config:           make(map[string]configEntry),
+		configGen:        make(map[string]uint64),
+		configSubs:       make(map[string][]ConfigurationEventHandler[Ctxt]),
+		PositionEncoding: PositionEncodingKind_UTF16,
 	}
 }
 
+/**
+ *	Serve runs the server over the given connection until the exit notification is received or the connection is closed.
+ *	The returned value is the process exit code mandated by the protocol.
+ */
+func (s *Server[Ctxt]) Serve(ctx context.Context, conn io.ReadWriteCloser) (int, error) {
+	port := jsonrpc.NewStreamPort(conn)
+
+	s.exitCode = 1
+	s.port = port
+	s.client = jsonrpc.NewClient(port)
+	s.server = jsonrpc.NewServer(s.processRequest, s.processNotification)
+
+	defer s.closeWatchers()
+
+	if err := s.server.Serve(ctx, port, s.client); err != nil {
+		return s.ExitCode(), err
+	}
+
+	return s.ExitCode(), nil
+}
+
+/**
+ *	ServeStdio runs the server over the process standard input and output.
+ */
+func (s *Server[Ctxt]) ServeStdio(ctx context.Context) (int, error) {
+	return s.Serve(ctx, stdio{})
+}
+
+func (s *Server[Ctxt]) Methods() *MethodSet[Ctxt] {
+	return s.methods
+}
+
 func (s *Server[Ctxt]) Server() *jsonrpc.Server {
 	return s.server
 }
@@ -50,6 +117,16 @@ func (s *Server[Ctxt]) ClientInfo() ClientInfo {
 	return s.clientInfo
 }
 
+/**
+ *	NegotiatedPositionEncoding returns the position encoding agreed upon with the client during initialization.
+ */
+func (s *Server[Ctxt]) NegotiatedPositionEncoding() PositionEncodingKind {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.posEncoding
+}
+
 func (s *Server[Ctxt]) Context() Ctxt {
 	return s.ctxt
 }
@@ -79,3 +156,21 @@ type ClientInfo struct {
 	ProcessId   int
 	Capabilites ClientCapabilities
 }
+
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error) {
+	return os.Stdin.Read(p)
+}
+
+func (stdio) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+func (stdio) Close() error {
+	if err := os.Stdin.Close(); err != nil {
+		return err
+	}
+
+	return os.Stdout.Close()
+}
@@ -0,0 +1,254 @@
+package lsp
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	SignatureHelpProvider interface answers textDocument/signatureHelp requests.
+ *	Offset parameter labels are byte offsets in the signature label; they are converted to the negotiated position
+ *	encoding, or to substrings for clients without label offset support.
+ */
+type SignatureHelpProvider interface {
+	SignatureHelpOptions() SignatureHelpOptions
+	SignatureHelp(ctx context.Context, params SignatureHelpParams) (*SignatureHelp, error)
+}
+
+/**
+ *	AddSignatureHelpMethods adds the signature help method dispatching to provider to the set and advertises its
+ *	trigger and retrigger characters.
+ */
+func AddSignatureHelpMethods[Ctxt any](set *MethodSet[Ctxt], provider SignatureHelpProvider) {
+	opts := provider.SignatureHelpOptions()
+
+	set.Add(NewRequest(Method_SignatureHelp, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params SignatureHelpParams) (*SignatureHelp, error) {
+		help, err := provider.SignatureHelp(ctx, params)
+		if err != nil || help == nil {
+			return nil, err
+		}
+
+		res := adaptSignatureHelp(*help, params.Context, srv.ClientInfo().Capabilites, srv.NegotiatedPositionEncoding())
+		return &res, nil
+	}))
+
+	set.AddCapabilities(func(caps *ServerCapabilities) {
+		if caps.SignatureHelpProvider == nil {
+			caps.SignatureHelpProvider = &opts
+		}
+	})
+}
+
+/**
+ *	ActiveParameter returns the index of the parameter being typed given the argument text between the opening
+ *	parenthesis of a call and the cursor, by counting the commas outside nested brackets and string literals.
+ */
+func ActiveParameter(args string) uint {
+	var res uint
+	var depth int
+	var quote rune
+	var escaped bool
+
+	for _, ch := range args {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if ch == '\\' && quote != '`' {
+				escaped = true
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			if depth > 0 {
+				depth--
+			}
+		case ch == ',' && depth == 0:
+			res++
+		}
+	}
+
+	return res
+}
+
+/**
+ *	adaptSignatureHelp converts the result of a provider to what the client supports:
+ *	the active signature is kept from the previous help when retriggering, parameter label offsets are expressed in
+ *	encoding and per signature active parameters are moved to the help when the client does not support them.
+ */
+func adaptSignatureHelp(help SignatureHelp, sctx *SignatureHelpContext, caps ClientCapabilities, encoding PositionEncodingKind) SignatureHelp {
+	var info *SignatureHelpClientCapabilitiesSignatureInformation
+
+	if caps.TextDocument != nil && caps.TextDocument.SignatureHelp != nil {
+		info = caps.TextDocument.SignatureHelp.SignatureInformation
+	}
+
+	offsets := info != nil && info.ParameterInformation != nil && info.ParameterInformation.LabelOffsetSupport
+	activeParams := info != nil && info.ActiveParameterSupport
+
+	if help.ActiveSignature == nil && sctx != nil && sctx.IsRetrigger && sctx.ActiveSignatureHelp != nil && sctx.ActiveSignatureHelp.ActiveSignature != nil {
+		if active := *sctx.ActiveSignatureHelp.ActiveSignature; active < uint(len(help.Signatures)) {
+			help.ActiveSignature = &active
+		}
+	}
+
+	signatures := make([]SignatureInformation, len(help.Signatures))
+
+	for sidx, sig := range help.Signatures {
+		if sig.Parameters != nil {
+			params := make([]ParameterInformation, len(*sig.Parameters))
+
+			for pidx, param := range *sig.Parameters {
+				if param.Label.Opt2 != nil {
+					start, end := labelOffsets(sig.Label, *param.Label.Opt2)
+
+					if offsets {
+						param.Label = NewParameterLabelOffsets(encoding.Length(sig.Label[:start]), encoding.Length(sig.Label[:end]))
+					} else {
+						param.Label = NewParameterLabel(sig.Label[start:end])
+					}
+				}
+
+				params[pidx] = param
+			}
+
+			sig.Parameters = &params
+		}
+
+		if sig.ActiveParameter != nil && !activeParams {
+			active := uint(0)
+
+			if help.ActiveSignature != nil {
+				active = *help.ActiveSignature
+			}
+
+			if uint(sidx) == active && help.ActiveParameter == nil {
+				help.ActiveParameter = sig.ActiveParameter
+			}
+
+			sig.ActiveParameter = nil
+		}
+
+		signatures[sidx] = sig
+	}
+
+	help.Signatures = signatures
+	return help
+}
+
+/**
+ *	labelOffsets returns the byte offsets of a parameter in label, clamped to the label and widened to whole runes.
+ *	Reversed offsets are invalid and yield an empty parameter label at the start offset.
+ */
+func labelOffsets(label string, offsets [2]uint) (uint, uint) {
+	start, end := clampLabelOffset(label, offsets[0], false), clampLabelOffset(label, offsets[1], true)
+
+	if end < start {
+		end = start
+	}
+
+	return start, end
+}
+
+func clampLabelOffset(label string, offset uint, forward bool) uint {
+	if offset > uint(len(label)) {
+		offset = uint(len(label))
+	}
+
+	for offset > 0 && offset < uint(len(label)) && !utf8.RuneStart(label[offset]) {
+		if forward {
+			offset++
+		} else {
+			offset--
+		}
+	}
+
+	return offset
+}
+
+func NewParameterLabel(label string) ParameterLabel {
+	return ParameterLabel{Opt1: &label}
+}
+
+func NewParameterLabelOffsets(start uint, end uint) ParameterLabel {
+	return ParameterLabel{Opt2: &[2]uint{start, end}}
+}
+
+// Supporting types
+const (
+	Method_SignatureHelp string = "textDocument/signatureHelp"
+)
+
+type SignatureHelpClientCapabilities struct {
+	DynamicRegistration  bool                                                 `json:"dynamicRegistration,omitempty"`
+	SignatureInformation *SignatureHelpClientCapabilitiesSignatureInformation `json:"signatureInformation,omitempty"`
+	ContextSupport       bool                                                 `json:"contextSupport,omitempty"`
+}
+
+type SignatureHelpClientCapabilitiesSignatureInformation struct {
+	DocumentationFormat    *[]MarkupKind                                                            `json:"documentationFormat,omitempty"`
+	ParameterInformation   *SignatureHelpClientCapabilitiesSignatureInformationParameterInformation `json:"parameterInformation,omitempty"`
+	ActiveParameterSupport bool                                                                     `json:"activeParameterSupport,omitempty"`
+}
+
+type SignatureHelpClientCapabilitiesSignatureInformationParameterInformation struct {
+	LabelOffsetSupport bool `json:"labelOffsetSupport,omitempty"`
+}
+
+type SignatureHelpOptions struct {
+	WorkDoneProgressOptions
+
+	TriggerCharacters   *[]string `json:"triggerCharacters,omitempty"`
+	RetriggerCharacters *[]string `json:"retriggerCharacters,omitempty"`
+}
+
+type SignatureHelpParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+
+	Context *SignatureHelpContext `json:"context,omitempty"`
+}
+
+type SignatureHelpContext struct {
+	TriggerKind         SignatureHelpTriggerKind `json:"triggerKind"`
+	TriggerCharacter    *string                  `json:"triggerCharacter,omitempty"`
+	IsRetrigger         bool                     `json:"isRetrigger"`
+	ActiveSignatureHelp *SignatureHelp           `json:"activeSignatureHelp,omitempty"`
+}
+
+type SignatureHelpTriggerKind int
+
+const (
+	SignatureHelpTriggerKind_Invoked          SignatureHelpTriggerKind = 1
+	SignatureHelpTriggerKind_TriggerCharacter SignatureHelpTriggerKind = 2
+	SignatureHelpTriggerKind_ContentChange    SignatureHelpTriggerKind = 3
+)
+
+type SignatureHelp struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature *uint                  `json:"activeSignature,omitempty"`
+	ActiveParameter *uint                  `json:"activeParameter,omitempty"`
+}
+
+type SignatureInformation struct {
+	Label           string                          `json:"label"`
+	Documentation   *Choice2[string, MarkupContent] `json:"documentation,omitempty"`
+	Parameters      *[]ParameterInformation         `json:"parameters,omitempty"`
+	ActiveParameter *uint                           `json:"activeParameter,omitempty"`
+}
+
+type ParameterInformation struct {
+	Label         ParameterLabel                  `json:"label"`
+	Documentation *Choice2[string, MarkupContent] `json:"documentation,omitempty"`
+}
+
+/**
+ *	ParameterLabel is either a substring of the signature label or the [start, end) offsets of the parameter in it.
+ */
+type ParameterLabel = Choice2[string, [2]uint]
@@ -0,0 +1,56 @@
+package lsp
+
+import "testing"
+
+func TestLabelOffsets(t *testing.T) {
+	const label = "f(aé, €b)"
+
+	tests := []struct {
+		offsets [2]uint
+		want    string
+	}{
+		{[2]uint{2, 5}, "aé"},
+		{[2]uint{7, 11}, "€b"},
+		// Offsets within a rune are widened to the whole rune.
+		{[2]uint{3, 4}, "é"},
+		{[2]uint{8, 9}, "€"},
+		// Offsets past the label are clamped, reversed ones give an empty label.
+		{[2]uint{7, 99}, "€b)"},
+		{[2]uint{5, 3}, ""},
+		{[2]uint{99, 1}, ""},
+	}
+
+	for _, test := range tests {
+		start, end := labelOffsets(label, test.offsets)
+
+		if start > end || label[start:end] != test.want {
+			t.Errorf("labelOffsets(%v) = %d, %d, want %q", test.offsets, start, end, test.want)
+		}
+	}
+}
+
+func TestAdaptSignatureHelpOffsets(t *testing.T) {
+	caps := ClientCapabilities{
+		TextDocument: &TextDocumentClientCapabilities{
+			SignatureHelp: &SignatureHelpClientCapabilities{
+				SignatureInformation: &SignatureHelpClientCapabilitiesSignatureInformation{
+					ParameterInformation: &SignatureHelpClientCapabilitiesSignatureInformationParameterInformation{LabelOffsetSupport: true},
+				},
+			},
+		},
+	}
+
+	params := []ParameterInformation{{Label: NewParameterLabelOffsets(7, 11)}, {Label: NewParameterLabelOffsets(5, 3)}}
+	help := SignatureHelp{Signatures: []SignatureInformation{{Label: "f(aé, €b)", Parameters: &params}}}
+
+	res := adaptSignatureHelp(help, nil, caps, PositionEncodingKind_UTF16)
+	got := *res.Signatures[0].Parameters
+
+	if *got[0].Label.Opt2 != [2]uint{6, 8} {
+		t.Errorf("first parameter label is %v, want [6 8]", *got[0].Label.Opt2)
+	}
+
+	if *got[1].Label.Opt2 != [2]uint{4, 4} {
+		t.Errorf("reversed parameter label is %v, want [4 4]", *got[1].Label.Opt2)
+	}
+}
@@ -0,0 +1,440 @@
+package lsp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/**
+ *	SnippetBuilder struct builds snippet strings, escaping the literal text it is given.
+ *	Tab stops are numbered in the order they are added, starting at 1.
+ */
+type SnippetBuilder struct {
+	buf  strings.Builder
+	next int
+}
+
+func NewSnippetBuilder() *SnippetBuilder {
+	return &SnippetBuilder{
+		next: 1,
+	}
+}
+
+func (b *SnippetBuilder) Text(text string) *SnippetBuilder {
+	b.buf.WriteString(EscapeSnippetText(text))
+	return b
+}
+
+func (b *SnippetBuilder) Tabstop() *SnippetBuilder {
+	fmt.Fprintf(&b.buf, "$%d", b.tabstop())
+	return b
+}
+
+func (b *SnippetBuilder) FinalTabstop() *SnippetBuilder {
+	b.buf.WriteString("$0")
+	return b
+}
+
+func (b *SnippetBuilder) Placeholder(text string) *SnippetBuilder {
+	fmt.Fprintf(&b.buf, "${%d:%s}", b.tabstop(), EscapeSnippetText(text))
+	return b
+}
+
+func (b *SnippetBuilder) Choice(options ...string) *SnippetBuilder {
+	escaped := make([]string, len(options))
+
+	for idx, option := range options {
+		escaped[idx] = escapeSnippet(option, `\,|`)
+	}
+
+	fmt.Fprintf(&b.buf, "${%d|%s|}", b.tabstop(), strings.Join(escaped, ","))
+	return b
+}
+
+/**
+ *	Variable inserts the value of the named variable, or def when the variable is unknown or empty.
+ */
+func (b *SnippetBuilder) Variable(name string, def string) *SnippetBuilder {
+	if def == "" {
+		fmt.Fprintf(&b.buf, "${%s}", name)
+	} else {
+		fmt.Fprintf(&b.buf, "${%s:%s}", name, EscapeSnippetText(def))
+	}
+
+	return b
+}
+
+func (b *SnippetBuilder) String() string {
+	return b.buf.String()
+}
+
+func (b *SnippetBuilder) tabstop() int {
+	res := b.next
+	b.next++
+	return res
+}
+
+/**
+ *	EscapeSnippetText escapes text so that it is inserted literally by a snippet.
+ */
+func EscapeSnippetText(text string) string {
+	return escapeSnippet(text, `\$}`)
+}
+
+func escapeSnippet(text string, special string) string {
+	var buf strings.Builder
+
+	for _, ch := range text {
+		if strings.ContainsRune(special, ch) {
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteRune(ch)
+	}
+
+	return buf.String()
+}
+
+/**
+ *	ValidateSnippet reports whether snippet follows the snippet syntax.
+ */
+func ValidateSnippet(snippet string) error {
+	_, err := SnippetToPlainText(snippet)
+	return err
+}
+
+/**
+ *	SnippetToPlainText returns the text a snippet inserts when nothing is typed in it: placeholders are replaced with their
+ *	default text, choices with their first option and variables with their default, or their name when they have none.
+ */
+func SnippetToPlainText(snippet string) (string, error) {
+	parser := snippetParser{src: snippet}
+
+	res, err := parser.parseSequence(false)
+	if err != nil {
+		return "", err
+	}
+
+	return res, nil
+}
+
+type snippetParser struct {
+	src string
+	pos int
+}
+
+func (p *snippetParser) parseSequence(nested bool) (string, error) {
+	var buf strings.Builder
+
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+
+		switch {
+		case ch == '\\' && p.pos+1 < len(p.src) && strings.IndexByte(`\$}`, p.src[p.pos+1]) >= 0:
+			buf.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		case ch == '}' && nested:
+			return buf.String(), nil
+		case ch == '$':
+			text, err := p.parseDollar()
+			if err != nil {
+				return "", err
+			}
+
+			buf.WriteString(text)
+		default:
+			buf.WriteByte(ch)
+			p.pos++
+		}
+	}
+
+	if nested {
+		return "", p.errorf("unterminated placeholder")
+	}
+
+	return buf.String(), nil
+}
+
+func (p *snippetParser) parseDollar() (string, error) {
+	p.pos++
+
+	switch {
+	case p.pos >= len(p.src):
+		return "$", nil
+	case isSnippetDigit(p.peek()):
+		p.parseInt()
+		return "", nil
+	case isSnippetVarStart(p.peek()):
+		return p.parseVar(), nil
+	case p.peek() != '{':
+		// A lone $ is inserted literally.
+		return "$", nil
+	}
+
+	p.pos++
+
+	switch {
+	case p.pos < len(p.src) && isSnippetDigit(p.peek()):
+		p.parseInt()
+
+		switch p.peek() {
+		case '}':
+			p.pos++
+			return "", nil
+		case ':':
+			p.pos++
+			return p.parseNested()
+		case '|':
+			p.pos++
+			return p.parseChoice()
+		case '/':
+			return "", p.parseTransform()
+		}
+	case p.pos < len(p.src) && isSnippetVarStart(p.peek()):
+		name := p.parseVar()
+
+		switch p.peek() {
+		case '}':
+			p.pos++
+			return name, nil
+		case ':':
+			p.pos++
+			return p.parseNested()
+		case '/':
+			return name, p.parseTransform()
+		}
+	}
+
+	return "", p.errorf("invalid tab stop, placeholder or variable")
+}
+
+func (p *snippetParser) parseNested() (string, error) {
+	text, err := p.parseSequence(true)
+	if err != nil {
+		return "", err
+	}
+
+	p.pos++
+	return text, nil
+}
+
+func (p *snippetParser) parseChoice() (string, error) {
+	var options []string
+	var buf strings.Builder
+
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+
+		switch {
+		case ch == '\\' && p.pos+1 < len(p.src) && strings.IndexByte(`\,|`, p.src[p.pos+1]) >= 0:
+			buf.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		case ch == ',':
+			options = append(options, buf.String())
+			buf.Reset()
+			p.pos++
+		case ch == '|':
+			if p.pos+1 >= len(p.src) || p.src[p.pos+1] != '}' {
+				return "", p.errorf("choice must end with '|}'")
+			}
+
+			options = append(options, buf.String())
+			p.pos += 2
+			return options[0], nil
+		default:
+			buf.WriteByte(ch)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf("unterminated choice")
+}
+
+/**
+ *	parseTransform checks the /regex/format/options} part of a transformed tab stop or variable.
+ */
+func (p *snippetParser) parseTransform() error {
+	p.pos++
+
+	for part := 0; part < 2; part++ {
+		for {
+			if p.pos >= len(p.src) {
+				return p.errorf("unterminated transform")
+			}
+
+			ch := p.src[p.pos]
+
+			if ch == '\\' && p.pos+1 < len(p.src) {
+				p.pos += 2
+				continue
+			}
+
+			// Format references such as ${1:/upcase} may contain slashes.
+			if part == 1 && ch == '$' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{' {
+				end := strings.IndexByte(p.src[p.pos:], '}')
+				if end < 0 {
+					return p.errorf("unterminated transform format")
+				}
+
+				p.pos += end + 1
+				continue
+			}
+
+			p.pos++
+
+			if ch == '/' {
+				break
+			}
+		}
+	}
+
+	for p.pos < len(p.src) && p.peek() >= 'a' && p.peek() <= 'z' {
+		p.pos++
+	}
+
+	if p.peek() != '}' {
+		return p.errorf("unterminated transform")
+	}
+
+	p.pos++
+	return nil
+}
+
+func (p *snippetParser) parseInt() int {
+	start := p.pos
+
+	for p.pos < len(p.src) && isSnippetDigit(p.peek()) {
+		p.pos++
+	}
+
+	res, _ := strconv.Atoi(p.src[start:p.pos])
+	return res
+}
+
+func (p *snippetParser) parseVar() string {
+	start := p.pos
+
+	for p.pos < len(p.src) && (isSnippetVarStart(p.peek()) || isSnippetDigit(p.peek())) {
+		p.pos++
+	}
+
+	return p.src[start:p.pos]
+}
+
+func (p *snippetParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *snippetParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid snippet at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func isSnippetDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isSnippetVarStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+/**
+ *	supportsSnippets reports whether the client accepts snippets in completion items.
+ */
+func supportsSnippets(caps ClientCapabilities) bool {
+	return caps.TextDocument != nil && caps.TextDocument.Completion != nil && caps.TextDocument.Completion.CompletionItem != nil && caps.TextDocument.Completion.CompletionItem.SnippetSupport
+}
+
+/**
+ *	DowngradeSnippets converts the snippet items of the list to plain text, as required by clients without snippet support.
+ */
+func (l *CompletionList) DowngradeSnippets() {
+	listSnippet := false
+
+	if l.ItemDefaults != nil && l.ItemDefaults.InsertTextFormat != nil && *l.ItemDefaults.InsertTextFormat == InsertTextFormat_Snippet {
+		plain := InsertTextFormat_PlainText
+
+		l.ItemDefaults.InsertTextFormat = &plain
+		listSnippet = true
+	}
+
+	for idx := range l.Items {
+		if listSnippet && l.Items[idx].InsertTextFormat == nil {
+			snippet := InsertTextFormat_Snippet
+			l.Items[idx].InsertTextFormat = &snippet
+		}
+
+		l.Items[idx].DowngradeSnippet()
+	}
+}
+
+/**
+ *	DowngradeSnippet converts the item to plain text if it is a snippet. Invalid snippets are inserted as is.
+ */
+func (i *CompletionItem) DowngradeSnippet() {
+	if i.InsertTextFormat == nil || *i.InsertTextFormat != InsertTextFormat_Snippet {
+		return
+	}
+
+	plain := InsertTextFormat_PlainText
+	i.InsertTextFormat = &plain
+
+	// Pointed values may be shared with the caller, they are replaced rather than modified.
+	downgrade := func(text string) string {
+		if res, err := SnippetToPlainText(text); err == nil {
+			return res
+		}
+
+		return text
+	}
+
+	if i.InsertText != nil {
+		text := downgrade(*i.InsertText)
+		i.InsertText = &text
+	}
+
+	if i.TextEditText != nil {
+		text := downgrade(*i.TextEditText)
+		i.TextEditText = &text
+	}
+
+	if i.TextEdit != nil {
+		edit := *i.TextEdit
+
+		if edit.Opt1 != nil {
+			opt := *edit.Opt1
+			opt.NewText = downgrade(opt.NewText)
+			edit.Opt1 = &opt
+		}
+
+		if edit.Opt2 != nil {
+			opt := *edit.Opt2
+			opt.NewText = downgrade(opt.NewText)
+			edit.Opt2 = &opt
+		}
+
+		i.TextEdit = &edit
+	}
+}
+
+/**
+ *	downgradeCompletionItems returns a plain text copy of items, whose insertTextFormat defaults to format.
+ */
+func downgradeCompletionItems(items []CompletionItem, format *InsertTextFormat) []CompletionItem {
+	res := make([]CompletionItem, len(items))
+
+	for idx, item := range items {
+		if item.InsertTextFormat == nil {
+			item.InsertTextFormat = format
+		}
+
+		item.DowngradeSnippet()
+		res[idx] = item
+	}
+
+	return res
+}
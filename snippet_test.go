@@ -0,0 +1,107 @@
+package lsp
+
+import "testing"
+
+func TestSnippetBuilder(t *testing.T) {
+	got := NewSnippetBuilder().
+		Text("func ").
+		Placeholder("name").
+		Text("(").
+		Tabstop().
+		Text(") {$}").
+		Choice("a,b", "c|d").
+		Variable("TM_FILENAME", "").
+		Variable("TM_SELECTED_TEXT", "x}").
+		FinalTabstop().
+		String()
+	want := `func ${1:name}($2) {\$\}${3|a\,b,c\|d|}${TM_FILENAME}${TM_SELECTED_TEXT:x\}}$0`
+
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEscapeSnippetText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"$1", `\$1`},
+		{"a}b", `a\}b`},
+		{`back\slash`, `back\\slash`},
+		{"{ok}", `{ok\}`},
+	}
+
+	for _, test := range tests {
+		got := EscapeSnippetText(test.text)
+		if got != test.want {
+			t.Errorf("EscapeSnippetText(%q) = %q, want %q", test.text, got, test.want)
+		}
+
+		plain, err := SnippetToPlainText(got)
+		if err != nil || plain != test.text {
+			t.Errorf("SnippetToPlainText(%q) = %q, %v, want %q", got, plain, err, test.text)
+		}
+	}
+}
+
+func TestSnippetToPlainText(t *testing.T) {
+	tests := []struct {
+		snippet string
+		want    string
+		invalid bool
+	}{
+		{snippet: "text", want: "text"},
+		{snippet: "a$1b$0", want: "ab"},
+		{snippet: "${1:default}", want: "default"},
+		{snippet: "${1:outer ${2:inner}}", want: "outer inner"},
+		{snippet: "${1|one,two|}", want: "one"},
+		{snippet: `${1|a\,b,c|}`, want: "a,b"},
+		{snippet: "${TM_FILENAME}", want: "TM_FILENAME"},
+		{snippet: "${TM_FILENAME:name}", want: "name"},
+		{snippet: "$TM_FILENAME.go", want: "TM_FILENAME.go"},
+		{snippet: "${TM_FILENAME/(.*)/${1:/upcase}/}", want: "TM_FILENAME"},
+		{snippet: "${TM_FILENAME/a\\/b/c/g}", want: "TM_FILENAME"},
+		{snippet: `\$1 \} \\`, want: `$1 } \`},
+		{snippet: "${1:unterminated", invalid: true},
+		{snippet: "${1|a,b}", invalid: true},
+		{snippet: "${}", invalid: true},
+	}
+
+	for _, test := range tests {
+		got, err := SnippetToPlainText(test.snippet)
+
+		if test.invalid {
+			if err == nil {
+				t.Errorf("SnippetToPlainText(%q) = %q, want an error", test.snippet, got)
+			}
+
+			continue
+		}
+
+		if err != nil || got != test.want {
+			t.Errorf("SnippetToPlainText(%q) = %q, %v, want %q", test.snippet, got, err, test.want)
+		}
+	}
+}
+
+func TestDowngradeSnippet(t *testing.T) {
+	snippet := InsertTextFormat_Snippet
+	insert := "fmt.Println(${1:msg})$0"
+	item := CompletionItem{
+		Label:            "println",
+		InsertTextFormat: &snippet,
+		InsertText:       &insert,
+	}
+
+	item.DowngradeSnippet()
+
+	if *item.InsertTextFormat != InsertTextFormat_PlainText || *item.InsertText != "fmt.Println(msg)" {
+		t.Errorf("downgraded item is %v %q", *item.InsertTextFormat, *item.InsertText)
+	}
+
+	if insert != "fmt.Println(${1:msg})$0" {
+		t.Errorf("caller text was modified to %q", insert)
+	}
+}
@@ -1,13 +1,68 @@
 package lsp
 
+import (
+	"context"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	TextDocumentProvider interface handles the text document synchronization notifications.
+ *	DidChange receives the position encoding negotiated with the client, in which the ranges of changes are expressed.
+ */
 type TextDocumentProvider interface {
 	DidOpen(document TextDocumentItem)
 	DidClose(document TextDocumentIdentifier)
-	DidChange(document VersionedTextDocumentIdentifier, changes []TextDocumentContentChangeEvent)
+	DidChange(document VersionedTextDocumentIdentifier, changes []TextDocumentContentChangeEvent, encoding PositionEncodingKind)
 	WillSave(document TextDocumentIdentifier, reason TextDocumentSaveReason)
 	DidSave(document TextDocumentIdentifier, text *string)
 }
 
+/**
+ *	WillSaveWaitUntilProvider may be implemented by a TextDocumentProvider to answer textDocument/willSaveWaitUntil requests.
+ */
+type WillSaveWaitUntilProvider interface {
+	WillSaveWaitUntil(ctx context.Context, document TextDocumentIdentifier, reason TextDocumentSaveReason) ([]TextEdit, error)
+}
+
+/**
+ *	AddTextDocumentMethods adds the text document synchronization methods dispatching to provider to the set.
+ *	textDocument/willSaveWaitUntil is only added when provider implements WillSaveWaitUntilProvider.
+ */
+func AddTextDocumentMethods[Ctxt any](set *MethodSet[Ctxt], provider TextDocumentProvider) {
+	set.Add(NewNotification(Method_DidOpenTextDocument, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidOpenTextDocumentParams) error {
+		provider.DidOpen(params.TextDocument)
+		return nil
+	}))
+	set.Add(NewNotification(Method_DidCloseTextDocument, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidCloseTextDocumentParams) error {
+		provider.DidClose(params.TextDocument)
+		return nil
+	}))
+	set.Add(NewNotification(Method_DidChangeTextDocument, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidChangeTextDocumentParams) error {
+		provider.DidChange(params.TextDocument, params.ContentChanges, srv.NegotiatedPositionEncoding())
+		return nil
+	}))
+	set.Add(NewNotification(Method_WillSaveTextDocument, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params WillSaveTextDocumentParams) error {
+		provider.WillSave(params.TextDocument, params.Reason)
+		return nil
+	}))
+	set.Add(NewNotification(Method_DidSaveTextDocument, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidSaveTextDocumentParams) error {
+		provider.DidSave(params.TextDocument, params.Text)
+		return nil
+	}))
+
+	if wswu, ok := provider.(WillSaveWaitUntilProvider); ok {
+		set.Add(NewRequest(Method_WillSaveWaitUntil, ClientToServer, func(ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, id jsonrpc.RequestId, params WillSaveTextDocumentParams) (*[]TextEdit, error) {
+			edits, err := wswu.WillSaveWaitUntil(ctx, params.TextDocument, params.Reason)
+			if err != nil {
+				return nil, err
+			}
+
+			return &edits, nil
+		}))
+	}
+}
+
 // Supporting types
 const (
 	Method_DidOpenTextDocument   string = "textDocument/didOpen"
@@ -43,13 +98,13 @@ type DidCloseTextDocumentParams struct {
 
 type TextDocumentClientCapabilities struct {
 	Synchronization *TextDocumentSyncClientCapabilities `json:"synchronization,omitempty"`
-	// Completion         *CompletionClientCapabilities               `json:"completion,omitempty"`
-	// Hover              *HoverClientCapabilities                    `json:"hover,omitempty"`
-	// SignatureHelp      *SignatureHelpClientCapabilities            `json:"signatureHelp,omitempty"`
-	// Declaration        *DeclarationClientCapabilities              `json:"declaration,omitempty"`
-	// Definition         *DefinitionClientCapabilities               `json:"definition,omitempty"`
-	// TypeDefinition     *TypeDefinitionClientCapabilities           `json:"typeDefinition,omitempty"`
-	// Implementation     *ImplementationClientCapabilities           `json:"implementation,omitempty"`
+	Completion      *CompletionClientCapabilities       `json:"completion,omitempty"`
+	Hover           *HoverClientCapabilities            `json:"hover,omitempty"`
+	SignatureHelp   *SignatureHelpClientCapabilities    `json:"signatureHelp,omitempty"`
+	Declaration     *DeclarationClientCapabilities      `json:"declaration,omitempty"`
+	Definition      *DefinitionClientCapabilities       `json:"definition,omitempty"`
+	TypeDefinition  *TypeDefinitionClientCapabilities   `json:"typeDefinition,omitempty"`
+	Implementation  *ImplementationClientCapabilities   `json:"implementation,omitempty"`
 	// References         *ReferenceClientCapabilities                `json:"references,omitempty"`
 	// DocumentHighlight  *DocumentHighlightClientCapabilities        `json:"documentHighlight,omitempty"`
 	// DocumentSymbol     *DocumentSymbolClientCapabilities           `json:"documentSymbol,omitempty"`
@@ -175,7 +230,7 @@ type TextDocumentIdentifier struct {
 
 type DocumentSelector []Choice2[string, DocumentFilter]
 
-type DocumentFilter Choice2[TextDocumentFilter, NotebookCellTextDocumentFilter]
+type DocumentFilter = Choice2[TextDocumentFilter, NotebookCellTextDocumentFilter]
 
 type NotebookCellTextDocumentFilter struct {
 	Notebook Choice2[string, NotebookDocumentFilter] `json:"notebook"`
@@ -183,13 +238,13 @@ type NotebookCellTextDocumentFilter struct {
 }
 
 type TextDocumentFilter struct {
-	Language string `json:"language"`
+	Language string `json:"language,omitempty"`
 	Scheme   string `json:"scheme,omitempty"`
 	Pattern  string `json:"pattern,omitempty"`
 }
 
 type NotebookDocumentFilter struct {
-	NotebookType string `json:"notebookType"`
+	NotebookType string `json:"notebookType,omitempty"`
 	Scheme       string `json:"scheme,omitempty"`
 	Pattern      string `json:"pattern,omitempty"`
 }
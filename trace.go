@@ -0,0 +1,67 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+// Supporting types
+const (
+	SetTraceMethod string = "$/setTrace"
+	LogTraceMethod string = "$/logTrace"
+)
+
+type SetTraceParams struct {
+	Value InitialTraceValue `json:"value"`
+}
+
+type LogTraceParams struct {
+	Message string  `json:"message"`
+	Verbose *string `json:"verbose,omitempty"`
+}
+
+func addTraceMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(SetTraceMethod, ClientToServer, processSetTrace[Ctxt]))
+	set.Add(NewServerToClientNotification[Ctxt, LogTraceParams](LogTraceMethod, nil))
+}
+
+func processSetTrace[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params SetTraceParams) error {
+	srv.setTrace(params.Value)
+	return nil
+}
+
+func (s *Server[Ctxt]) Trace() InitialTraceValue {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.trace
+}
+
+func (s *Server[Ctxt]) setTrace(trace InitialTraceValue) {
+	s.lock.Lock()
+	s.trace = trace
+	s.lock.Unlock()
+}
+
+/**
+ *	LogTrace sends message to the client through $/logTrace unless tracing is off.
+ *	verbose is only included when the trace level is verbose.
+ */
+func (s *Server[Ctxt]) LogTrace(message string, verbose string) error {
+	trace := s.Trace()
+
+	if trace == InitialTraceValue_Off || trace == "" {
+		return nil
+	}
+
+	params := LogTraceParams{
+		Message: message,
+	}
+
+	if trace == InitialTraceValue_Verbose && verbose != "" {
+		params.Verbose = &verbose
+	}
+
+	return s.Notify(LogTraceMethod, params)
+}
@@ -0,0 +1,250 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+type FilesChangedEventHandler[Ctxt any] func(srv *Server[Ctxt], changes []FileEvent)
+
+/**
+ *	DefaultFilesChangedDelay is the debounce delay of file changes used when the FilesChangedDelay of a Server is zero.
+ */
+const DefaultFilesChangedDelay time.Duration = 100 * time.Millisecond
+
+/**
+ *	localWatcher interface is implemented by the platform specific watchers used when the client cannot watch files.
+ */
+type localWatcher interface {
+	Close() error
+}
+
+func addWatchMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(DidChangeWatchedFilesMethod, ClientToServer, processDidChangeWatchedFiles[Ctxt]))
+}
+
+func processDidChangeWatchedFiles[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidChangeWatchedFilesParams) error {
+	srv.queueFileEvents(params.Changes)
+	return nil
+}
+
+/**
+ *	WatchFiles asks the client to watch the files matching watchers through client/registerCapability.
+ *	When the client does not support it, the workspace folders are watched locally instead, directories that cannot be
+ *	watched being reported through window/logMessage; version control and dependency directories are skipped unless
+ *	a pattern names them. In both cases changes are reported through OnFilesChanged, debounced by FilesChangedDelay
+ *	or DefaultFilesChangedDelay when it is zero.
+ */
+func (s *Server[Ctxt]) WatchFiles(ctx context.Context, watchers []FileSystemWatcher) error {
+	if s.SupportsDynamicRegistration(DidChangeWatchedFilesMethod) {
+		_, err := s.RegisterCapability(ctx, DidChangeWatchedFilesMethod, DidChangeWatchedFilesRegistrationOptions{Watchers: watchers})
+		return err
+	}
+
+	var roots []string
+
+	for _, folder := range s.WorkspaceFolders() {
+		if parsed, err := url.Parse(string(folder.Uri)); err == nil && parsed.Scheme == "file" {
+			roots = append(roots, parsed.Path)
+		}
+	}
+
+	watcher, err := newLocalWatcher(roots, watchers, s.queueFileEvents, func(err error) {
+		s.LogMessage(MessageType_Warning, fmt.Sprintf("file watching is incomplete: %s", err))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.watchers = append(s.watchers, watcher)
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *Server[Ctxt]) closeWatchers() {
+	s.lock.Lock()
+	watchers := s.watchers
+	s.watchers = nil
+	s.lock.Unlock()
+
+	for _, watcher := range watchers {
+		watcher.Close()
+	}
+}
+
+/**
+ *	queueFileEvents accumulates changes until no new change arrived for FilesChangedDelay, then reports them.
+ *	Successive changes of a same file are merged into a single event, a file created then deleted being dropped.
+ */
+func (s *Server[Ctxt]) queueFileEvents(changes []FileEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, change := range changes {
+		merged := false
+
+		for idx := range s.fileEvents {
+			if s.fileEvents[idx].Uri == change.Uri {
+				if typ, ok := mergeFileChangeType(s.fileEvents[idx].Type, change.Type); ok {
+					s.fileEvents[idx].Type = typ
+				} else {
+					s.fileEvents = append(s.fileEvents[:idx], s.fileEvents[idx+1:]...)
+				}
+
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			s.fileEvents = append(s.fileEvents, change)
+		}
+	}
+
+	if s.fileTimer != nil {
+		s.fileTimer.Stop()
+	}
+
+	delay := s.FilesChangedDelay
+
+	if delay <= 0 {
+		delay = DefaultFilesChangedDelay
+	}
+
+	s.fileTimer = time.AfterFunc(delay, s.flushFileEvents)
+}
+
+func (s *Server[Ctxt]) flushFileEvents() {
+	s.lock.Lock()
+	changes := s.fileEvents
+	s.fileEvents = nil
+	s.fileTimer = nil
+	s.lock.Unlock()
+
+	if len(changes) > 0 && s.OnFilesChanged != nil {
+		s.OnFilesChanged(s, changes)
+	}
+}
+
+/**
+ *	mergeFileChangeType returns the type of a change followed by another one of the same file,
+ *	or false when they cancel each other out.
+ */
+func mergeFileChangeType(prev FileChangeType, next FileChangeType) (FileChangeType, bool) {
+	switch {
+	case prev == FileChangeType_Created && next == FileChangeType_Changed:
+		return FileChangeType_Created, true
+	case prev == FileChangeType_Created && next == FileChangeType_Deleted:
+		return 0, false
+	case prev == FileChangeType_Deleted && next == FileChangeType_Created:
+		return FileChangeType_Changed, true
+	}
+
+	return next, true
+}
+
+/**
+ *	skipWatchDir reports whether the directory name is a version control or dependency directory that is not
+ *	explicitly named by a segment of any of the patterns of watchers.
+ */
+func skipWatchDir(name string, watchers []FileSystemWatcher) bool {
+	switch name {
+	case ".git", ".hg", ".svn", "node_modules", "vendor":
+	default:
+		return false
+	}
+
+	for _, watcher := range watchers {
+		for _, segment := range strings.Split(watcher.GlobPattern, "/") {
+			// Wildcard only segments such as ** would otherwise name every directory.
+			if strings.Trim(segment, "*") != "" && MatchGlob(segment, name, false) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+/**
+ *	matchesWatchers reports whether a local change of path must be reported according to watchers.
+ */
+func matchesWatchers(watchers []FileSystemWatcher, path string, typ FileChangeType) bool {
+	for _, watcher := range watchers {
+		kind := WatchKind_Create | WatchKind_Change | WatchKind_Delete
+
+		if watcher.Kind != nil {
+			kind = *watcher.Kind
+		}
+
+		if kind&typ.watchKind() != 0 && MatchGlob(watcher.GlobPattern, path, false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t FileChangeType) watchKind() WatchKind {
+	switch t {
+	case FileChangeType_Created:
+		return WatchKind_Create
+	case FileChangeType_Changed:
+		return WatchKind_Change
+	case FileChangeType_Deleted:
+		return WatchKind_Delete
+	}
+
+	return 0
+}
+
+// Supporting types
+const (
+	DidChangeWatchedFilesMethod string = "workspace/didChangeWatchedFiles"
+)
+
+type DidChangeWatchedFilesClientCapabilities struct {
+	DynamicRegistration    bool `json:"dynamicRegistration,omitempty"`
+	RelativePatternSupport bool `json:"relativePatternSupport,omitempty"`
+}
+
+type DidChangeWatchedFilesRegistrationOptions struct {
+	Watchers []FileSystemWatcher `json:"watchers"`
+}
+
+type FileSystemWatcher struct {
+	GlobPattern string     `json:"globPattern"`
+	Kind        *WatchKind `json:"kind,omitempty"`
+}
+
+type WatchKind uint
+
+const (
+	WatchKind_Create WatchKind = 1
+	WatchKind_Change WatchKind = 2
+	WatchKind_Delete WatchKind = 4
+)
+
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+type FileEvent struct {
+	Uri  DocumentUri    `json:"uri"`
+	Type FileChangeType `json:"type"`
+}
+
+type FileChangeType uint
+
+const (
+	FileChangeType_Created FileChangeType = 1
+	FileChangeType_Changed FileChangeType = 2
+	FileChangeType_Deleted FileChangeType = 3
+)
@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask uint32 = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+/**
+ *	inotifyWatcher struct watches directory trees with inotify, adding the directories created while it runs.
+ */
+type inotifyWatcher struct {
+	lock     sync.Mutex
+	fd       int
+	file     *os.File
+	dirs     map[int]string
+	watchers []FileSystemWatcher
+	emit     func([]FileEvent)
+	fail     func(error)
+}
+
+func newLocalWatcher(roots []string, watchers []FileSystemWatcher, emit func([]FileEvent), fail func(error)) (localWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &inotifyWatcher{
+		fd: fd,
+		// A non-blocking descriptor is handled by the runtime poller so that Close unblocks the reading goroutine.
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		dirs:     make(map[int]string),
+		watchers: watchers,
+		emit:     emit,
+		fail:     fail,
+	}
+
+	for _, root := range roots {
+		if err := w.addTree(root); err != nil {
+			w.fail(err)
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Close() error {
+	return w.file.Close()
+}
+
+/**
+ *	addTree watches root and its subdirectories, skipping the ones no watcher can be interested in.
+ *	Directories that cannot be watched are reported through fail and skipped, except when the inotify watch limit
+ *	is reached, which stops the walk and is returned.
+ */
+func (w *inotifyWatcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+
+		if path != root && skipWatchDir(entry.Name(), w.watchers) {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if errors.Is(err, syscall.ENOSPC) {
+			return fmt.Errorf("cannot watch '%s': %w", path, err)
+		} else if err != nil {
+			w.fail(fmt.Errorf("cannot watch '%s': %w", path, err))
+			return filepath.SkipDir
+		}
+
+		w.lock.Lock()
+		w.dirs[wd] = path
+		w.lock.Unlock()
+
+		return nil
+	})
+}
+
+func (w *inotifyWatcher) run() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		var changes []FileEvent
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			name := strings.TrimRight(string(buf[off+syscall.SizeofInotifyEvent:off+syscall.SizeofInotifyEvent+int(event.Len)]), "\x00")
+			off += syscall.SizeofInotifyEvent + int(event.Len)
+
+			w.lock.Lock()
+			dir, ok := w.dirs[int(event.Wd)]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, int(event.Wd))
+			}
+			w.lock.Unlock()
+
+			if !ok || name == "" {
+				continue
+			}
+
+			path := filepath.Join(dir, name)
+
+			var typ FileChangeType
+
+			switch {
+			case event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+				typ = FileChangeType_Created
+
+				if event.Mask&syscall.IN_ISDIR != 0 && !skipWatchDir(name, w.watchers) {
+					if err := w.addTree(path); err != nil {
+						w.fail(err)
+					}
+				}
+			case event.Mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+				typ = FileChangeType_Deleted
+			case event.Mask&(syscall.IN_MODIFY|syscall.IN_CLOSE_WRITE) != 0:
+				typ = FileChangeType_Changed
+			default:
+				continue
+			}
+
+			if matchesWatchers(w.watchers, filepath.ToSlash(path), typ) {
+				changes = append(changes, FileEvent{
+					Uri:  DocumentUri((&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()),
+					Type: typ,
+				})
+			}
+		}
+
+		if len(changes) > 0 {
+			w.emit(changes)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package lsp
+
+import "fmt"
+
+func newLocalWatcher(roots []string, watchers []FileSystemWatcher, emit func([]FileEvent), fail func(error)) (localWatcher, error) {
+	return nil, fmt.Errorf("local file watching is not supported on this platform")
+}
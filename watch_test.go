@@ -0,0 +1,101 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeFileChangeType(t *testing.T) {
+	tests := []struct {
+		prev FileChangeType
+		next FileChangeType
+		want FileChangeType
+		keep bool
+	}{
+		{FileChangeType_Created, FileChangeType_Changed, FileChangeType_Created, true},
+		{FileChangeType_Created, FileChangeType_Deleted, 0, false},
+		{FileChangeType_Changed, FileChangeType_Changed, FileChangeType_Changed, true},
+		{FileChangeType_Changed, FileChangeType_Deleted, FileChangeType_Deleted, true},
+		{FileChangeType_Deleted, FileChangeType_Created, FileChangeType_Changed, true},
+	}
+
+	for _, test := range tests {
+		if got, keep := mergeFileChangeType(test.prev, test.next); got != test.want || keep != test.keep {
+			t.Errorf("mergeFileChangeType(%d, %d) = %d, %t, want %d, %t", test.prev, test.next, got, keep, test.want, test.keep)
+		}
+	}
+}
+
+func TestSkipWatchDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    bool
+	}{
+		{"src", "**/*.go", false},
+		{"vendor", "**/*.go", true},
+		{".git", "**/*", true},
+		{"node_modules", "**/node_modules/**", false},
+		{"node_modules", "**/node_*/*.js", false},
+		{"vendor", "{vendor,third_party}/**", false},
+		// Mentioning the name within a segment does not name the directory.
+		{"vendor", "**/vendored.go", true},
+		{".git", "**/*.gitignore", true},
+	}
+
+	for _, test := range tests {
+		if got := skipWatchDir(test.name, []FileSystemWatcher{{GlobPattern: test.pattern}}); got != test.want {
+			t.Errorf("skipWatchDir(%q, %q) = %t, want %t", test.name, test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestQueueFileEvents(t *testing.T) {
+	tests := []struct {
+		changes [][]FileEvent
+		want    []FileEvent
+	}{
+		{
+			[][]FileEvent{{{"file:///a", FileChangeType_Created}}, {{"file:///a", FileChangeType_Changed}}},
+			[]FileEvent{{"file:///a", FileChangeType_Created}},
+		},
+		{
+			[][]FileEvent{{{"file:///a", FileChangeType_Created}, {"file:///b", FileChangeType_Changed}}, {{"file:///a", FileChangeType_Deleted}}},
+			[]FileEvent{{"file:///b", FileChangeType_Changed}},
+		},
+		{
+			[][]FileEvent{{{"file:///a", FileChangeType_Created}}, {{"file:///a", FileChangeType_Deleted}}},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		srv := NewServer[struct{}]()
+		reported := make(chan []FileEvent, len(test.changes))
+
+		srv.OnFilesChanged = func(srv *Server[struct{}], changes []FileEvent) {
+			reported <- changes
+		}
+
+		// A zero FilesChangedDelay still debounces the successive changes.
+		for _, changes := range test.changes {
+			srv.queueFileEvents(changes)
+		}
+
+		var got []FileEvent
+
+		select {
+		case got = <-reported:
+		case <-time.After(3 * DefaultFilesChangedDelay):
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v reported %v, want %v", test.changes, got, test.want)
+		}
+
+		if len(reported) > 0 {
+			t.Errorf("%v reported changes more than once", test.changes)
+		}
+	}
+}
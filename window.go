@@ -1,6 +1,94 @@
 package lsp
 
+import (
+	"context"
+	"encoding/json"
+)
+
 // Supporting types
+const (
+	ShowMessageMethod        string = "window/showMessage"
+	ShowMessageRequestMethod string = "window/showMessageRequest"
+	LogMessageMethod         string = "window/logMessage"
+	ShowDocumentMethod       string = "window/showDocument"
+)
+
+func addWindowMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewServerToClientNotification[Ctxt, ShowMessageParams](ShowMessageMethod, nil))
+	set.Add(NewServerToClientRequest[Ctxt, ShowMessageRequestParams, *MessageActionItem](ShowMessageRequestMethod, nil))
+	set.Add(NewServerToClientNotification[Ctxt, LogMessageParams](LogMessageMethod, nil))
+	set.Add(NewServerToClientRequest[Ctxt, ShowDocumentParams, ShowDocumentResult](ShowDocumentMethod, supportsShowDocument))
+}
+
+func (s *Server[Ctxt]) ShowMessage(typ MessageType, message string) error {
+	return s.Notify(ShowMessageMethod, ShowMessageParams{
+		Type:    typ,
+		Message: message,
+	})
+}
+
+func (s *Server[Ctxt]) LogMessage(typ MessageType, message string) error {
+	return s.Notify(LogMessageMethod, LogMessageParams{
+		Type:    typ,
+		Message: message,
+	})
+}
+
+/**
+ *	ShowMessageRequest asks the client to show a message with the given actions and returns the chosen one,
+ *	or nil when the message was dismissed.
+ *	Additional action properties are dropped when the client does not support them.
+ */
+func (s *Server[Ctxt]) ShowMessageRequest(ctx context.Context, typ MessageType, message string, actions []MessageActionItem) (*MessageActionItem, error) {
+	params := ShowMessageRequestParams{
+		Type:    typ,
+		Message: message,
+	}
+
+	if len(actions) > 0 {
+		items := make([]MessageActionItem, len(actions))
+
+		for idx, action := range actions {
+			items[idx] = action
+
+			if !s.supportsMessageActionProperties() {
+				items[idx].Properties = nil
+			}
+		}
+
+		params.Actions = &items
+	}
+
+	res, err := Call[Ctxt, ShowMessageRequestParams, *MessageActionItem](ctx, s, ShowMessageRequestMethod, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return *res, nil
+}
+
+/**
+ *	ShowDocument asks the client to display the given document and returns whether it succeeded.
+ *	It fails with ErrClientUnsupported without contacting the client when the client does not support the request.
+ */
+func (s *Server[Ctxt]) ShowDocument(ctx context.Context, params ShowDocumentParams) (bool, error) {
+	res, err := Call[Ctxt, ShowDocumentParams, ShowDocumentResult](ctx, s, ShowDocumentMethod, params)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Success, nil
+}
+
+func supportsShowDocument(caps ClientCapabilities) bool {
+	return caps.Window != nil && caps.Window.ShowDocument != nil && caps.Window.ShowDocument.Support
+}
+
+func (s *Server[Ctxt]) supportsMessageActionProperties() bool {
+	caps := s.ClientInfo().Capabilites
+	return caps.Window != nil && caps.Window.ShowMessage != nil && caps.Window.ShowMessage.MessageActionItem != nil && caps.Window.ShowMessage.MessageActionItem.AdditionalPropertiesSupport
+}
+
 type WindowClientCapabilities struct {
 	WorkDoneProgress bool                                  `json:"workDoneProgress,omitempty"`
 	ShowMessage      *ShowMessageRequestClientCapabilities `json:"showMessage,omitempty"`
@@ -18,3 +106,82 @@ type ShowMessageRequestClientCapabilitiesMessageActionItem struct {
 type ShowDocumentClientCapabilities struct {
 	Support bool `json:"support"`
 }
+
+type ShowDocumentParams struct {
+	Uri       Uri    `json:"uri"`
+	External  *bool  `json:"external,omitempty"`
+	TakeFocus *bool  `json:"takeFocus,omitempty"`
+	Selection *Range `json:"selection,omitempty"`
+}
+
+type ShowDocumentResult struct {
+	Success bool `json:"success"`
+}
+
+type MessageType int
+
+const (
+	MessageType_Error   MessageType = 1
+	MessageType_Warning MessageType = 2
+	MessageType_Info    MessageType = 3
+	MessageType_Log     MessageType = 4
+	MessageType_Debug   MessageType = 5
+)
+
+type ShowMessageParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+}
+
+type ShowMessageRequestParams struct {
+	Type    MessageType          `json:"type"`
+	Message string               `json:"message"`
+	Actions *[]MessageActionItem `json:"actions,omitempty"`
+}
+
+type LogMessageParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+}
+
+/**
+ *	MessageActionItem struct holds an action of a window/showMessageRequest.
+ *	Properties are encoded alongside title and are only sent to clients supporting additional properties.
+ */
+type MessageActionItem struct {
+	Title      string
+	Properties map[string]interface{}
+}
+
+func (i MessageActionItem) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]interface{}, len(i.Properties)+1)
+
+	for key, val := range i.Properties {
+		obj[key] = val
+	}
+
+	obj["title"] = i.Title
+	return json.Marshal(obj)
+}
+
+func (i *MessageActionItem) UnmarshalJSON(data []byte) error {
+	var obj map[string]interface{}
+
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*i = MessageActionItem{}
+
+	if title, ok := obj["title"].(string); ok {
+		i.Title = title
+	}
+
+	delete(obj, "title")
+
+	if len(obj) > 0 {
+		i.Properties = obj
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+/**
+ *	WorkDoneReporter struct reports the progress of a long running operation to the client through $/progress.
+ *	All its methods are no-ops when the client does not support work done progress, even if it supplied a token.
+ */
+type WorkDoneReporter[Ctxt any] struct {
+	srv    *Server[Ctxt]
+	token  ProgressToken
+	key    string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var workDoneTokenSeq uint64
+
+/**
+ *	NewWorkDoneReporter returns a reporter using the token supplied by the client in params, or a token created through
+ *	window/workDoneProgress/create, when the client supports work done progress.
+ *	The context of the reporter is cancelled when the client sends window/workDoneProgress/cancel for its token.
+ */
+func NewWorkDoneReporter[Ctxt any](ctx context.Context, srv *Server[Ctxt], params WorkDoneProgressParams) (*WorkDoneReporter[Ctxt], error) {
+	var token ProgressToken
+	var err error
+
+	// Without client support token stays nil, making the reporter a no-op.
+	if srv.supportsWorkDoneProgress() {
+		if params.WorkDoneToken != nil {
+			token = *params.WorkDoneToken
+		} else {
+			token = fmt.Sprintf("workdone-%d", atomic.AddUint64(&workDoneTokenSeq, 1))
+
+			if _, err = Call[Ctxt, WorkDoneProgressCreateParams, WorkDoneProgressCreateResult](ctx, srv, WorkDoneProgressCreateMethod, WorkDoneProgressCreateParams{Token: token}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rep := &WorkDoneReporter[Ctxt]{
+		srv:   srv,
+		token: token,
+	}
+
+	rep.ctx, rep.cancel = context.WithCancel(ctx)
+
+	if token == nil {
+		return rep, nil
+	}
+
+	if rep.key, err = jsonKey(token); err != nil {
+		rep.cancel()
+		return nil, err
+	}
+
+	srv.lock.Lock()
+	srv.progress[rep.key] = rep.cancel
+	srv.lock.Unlock()
+
+	return rep, nil
+}
+
+func (r *WorkDoneReporter[Ctxt]) Context() context.Context {
+	return r.ctx
+}
+
+func (r *WorkDoneReporter[Ctxt]) Token() ProgressToken {
+	return r.token
+}
+
+func (r *WorkDoneReporter[Ctxt]) Begin(title string) error {
+	return r.send(WorkDoneProgress{
+		Kind:  WorkDoneProgressKind_Begin,
+		Title: &title,
+	})
+}
+
+/**
+ *	BeginCancellable starts reporting progress of an operation the user may cancel, which must stop once Context is done.
+ */
+func (r *WorkDoneReporter[Ctxt]) BeginCancellable(title string) error {
+	cancellable := true
+
+	return r.send(WorkDoneProgress{
+		Kind:        WorkDoneProgressKind_Begin,
+		Title:       &title,
+		Cancellable: &cancellable,
+	})
+}
+
+func (r *WorkDoneReporter[Ctxt]) Report(message string, percentage uint) error {
+	value := WorkDoneProgress{
+		Kind:       WorkDoneProgressKind_Report,
+		Percentage: &percentage,
+	}
+
+	if message != "" {
+		value.Message = &message
+	}
+
+	return r.send(value)
+}
+
+/**
+ *	End sends the final progress notification and releases the token; the reporter must not be used afterwards.
+ */
+func (r *WorkDoneReporter[Ctxt]) End(message string) error {
+	value := WorkDoneProgress{
+		Kind: WorkDoneProgressKind_End,
+	}
+
+	if message != "" {
+		value.Message = &message
+	}
+
+	err := r.send(value)
+
+	if r.token != nil {
+		r.srv.lock.Lock()
+		delete(r.srv.progress, r.key)
+		r.srv.lock.Unlock()
+	}
+
+	r.cancel()
+	return err
+}
+
+func (r *WorkDoneReporter[Ctxt]) send(value WorkDoneProgress) error {
+	var pa *ProgressParams
+	var err error
+
+	if r.token == nil {
+		return nil
+	}
+
+	if pa, err = newProgressParams(r.token, value); err != nil {
+		return err
+	}
+
+	return sendProgress(r.srv.port, nil, *pa)
+}
+
+func (s *Server[Ctxt]) supportsWorkDoneProgress() bool {
+	return supportsWorkDoneProgress(s.ClientInfo().Capabilites)
+}
+
+func supportsWorkDoneProgress(caps ClientCapabilities) bool {
+	return caps.Window != nil && caps.Window.WorkDoneProgress
+}
+
+func addProgressMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(WorkDoneProgressCancelMethod, ClientToServer, processWorkDoneProgressCancel[Ctxt]))
+	set.Add(NewServerToClientRequest[Ctxt, WorkDoneProgressCreateParams, WorkDoneProgressCreateResult](WorkDoneProgressCreateMethod, supportsWorkDoneProgress))
+}
+
+func processWorkDoneProgressCancel[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params WorkDoneProgressCancelParams) error {
+	key, err := jsonKey(params.Token)
+	if err != nil {
+		return err
+	}
+
+	srv.lock.Lock()
+	cancel := srv.progress[key]
+	srv.lock.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	return nil
+}
@@ -1,6 +1,174 @@
 package lsp
 
+import (
+	"context"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/trwk76/jsonrpc"
+)
+
+type WorkspaceFoldersEventHandler[Ctxt any] func(srv *Server[Ctxt], event WorkspaceFoldersChangeEvent)
+
+func addWorkspaceMethods[Ctxt any](set *MethodSet[Ctxt]) {
+	set.Add(NewNotification(DidChangeWorkspaceFoldersMethod, ClientToServer, processDidChangeWorkspaceFolders[Ctxt]))
+	set.Add(NewServerToClientRequest[Ctxt, WorkspaceFoldersParams, *[]WorkspaceFolder](WorkspaceFoldersMethod, supportsWorkspaceFolders))
+}
+
+func processDidChangeWorkspaceFolders[Ctxt any](ctx context.Context, srv *Server[Ctxt], port jsonrpc.Port, hdrs *jsonrpc.HeaderSet, params DidChangeWorkspaceFoldersParams) error {
+	srv.changeWorkspaceFolders(params.Event)
+	return nil
+}
+
+/**
+ *	WorkspaceFolders returns the current workspace folders of the client.
+ */
+func (s *Server[Ctxt]) WorkspaceFolders() []WorkspaceFolder {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]WorkspaceFolder(nil), s.folders...)
+}
+
+/**
+ *	RefreshWorkspaceFolders fetches the workspace folders from the client through workspace/workspaceFolders
+ *	and replaces the current set with them.
+ */
+func (s *Server[Ctxt]) RefreshWorkspaceFolders(ctx context.Context) ([]WorkspaceFolder, error) {
+	res, err := Call[Ctxt, WorkspaceFoldersParams, *[]WorkspaceFolder](ctx, s, WorkspaceFoldersMethod, WorkspaceFoldersParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []WorkspaceFolder
+
+	if *res != nil {
+		folders = **res
+	}
+
+	current := s.WorkspaceFolders()
+	event := WorkspaceFoldersChangeEvent{
+		Added:   diffWorkspaceFolders(folders, current),
+		Removed: diffWorkspaceFolders(current, folders),
+	}
+
+	if len(event.Added) > 0 || len(event.Removed) > 0 {
+		s.changeWorkspaceFolders(event)
+	}
+
+	return folders, nil
+}
+
+/**
+ *	FolderOf returns the innermost workspace folder containing uri.
+ */
+func (s *Server[Ctxt]) FolderOf(uri DocumentUri) (WorkspaceFolder, bool) {
+	var res WorkspaceFolder
+	var found bool
+
+	for _, folder := range s.WorkspaceFolders() {
+		root := strings.TrimSuffix(string(folder.Uri), "/")
+
+		if string(uri) != root && !strings.HasPrefix(string(uri), root+"/") {
+			continue
+		}
+
+		if !found || len(folder.Uri) > len(res.Uri) {
+			res = folder
+			found = true
+		}
+	}
+
+	return res, found
+}
+
+func supportsWorkspaceFolders(caps ClientCapabilities) bool {
+	return caps.Workspace != nil && caps.Workspace.WorkspaceFolders
+}
+
+func (s *Server[Ctxt]) seedWorkspaceFolders(params InitializeParams) {
+	var folders []WorkspaceFolder
+
+	switch {
+	case params.WorkspaceFolders != nil:
+		folders = append(folders, *params.WorkspaceFolders...)
+	case params.RootUri != nil:
+		folders = append(folders, WorkspaceFolder{
+			Uri:  Uri(*params.RootUri),
+			Name: path.Base(strings.TrimSuffix(string(*params.RootUri), "/")),
+		})
+	case params.RootPath != nil && *params.RootPath != nil:
+		root := filepath.ToSlash(**params.RootPath)
+
+		if !strings.HasPrefix(root, "/") {
+			root = "/" + root
+		}
+
+		folders = append(folders, WorkspaceFolder{
+			Uri:  Uri((&url.URL{Scheme: "file", Path: root}).String()),
+			Name: path.Base(root),
+		})
+	}
+
+	s.lock.Lock()
+	s.folders = folders
+	s.lock.Unlock()
+}
+
+func (s *Server[Ctxt]) changeWorkspaceFolders(event WorkspaceFoldersChangeEvent) {
+	s.lock.Lock()
+	folders := diffWorkspaceFolders(s.folders, event.Removed)
+	s.folders = append(folders, diffWorkspaceFolders(event.Added, folders)...)
+	s.lock.Unlock()
+
+	if s.OnWorkspaceFoldersChanged != nil {
+		s.OnWorkspaceFoldersChanged(s, event)
+	}
+}
+
+/**
+ *	diffWorkspaceFolders returns the folders of a whose uri is not in b.
+ */
+func diffWorkspaceFolders(a []WorkspaceFolder, b []WorkspaceFolder) []WorkspaceFolder {
+	var res []WorkspaceFolder
+
+	for _, folder := range a {
+		found := false
+
+		for _, other := range b {
+			if other.Uri == folder.Uri {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			res = append(res, folder)
+		}
+	}
+
+	return res
+}
+
 // Supporting types
+const (
+	DidChangeWorkspaceFoldersMethod string = "workspace/didChangeWorkspaceFolders"
+	WorkspaceFoldersMethod          string = "workspace/workspaceFolders"
+)
+
+type DidChangeWorkspaceFoldersParams struct {
+	Event WorkspaceFoldersChangeEvent `json:"event"`
+}
+
+type WorkspaceFoldersChangeEvent struct {
+	Added   []WorkspaceFolder `json:"added"`
+	Removed []WorkspaceFolder `json:"removed"`
+}
+
+type WorkspaceFoldersParams Void
+
 type WorkspaceFolder struct {
 	Uri  Uri    `json:"uri"`
 	Name string `json:"name"`
@@ -9,8 +177,8 @@ type WorkspaceFolder struct {
 type WorkspaceClientCapabilities struct {
 	ApplyEdit bool `json:"applyEdit,omitempty"`
 	// WorkspaceEdit          *WorkspaceEditClientCapabilities           `json:"workspaceEdit,omitempty"`
-	// DidChangeConfiguration *DidChangeConfigurationClientCapabilities  `json:"didChangeConfiguration,omitempty"`
-	// DidChangeWatchedFiles  *DidChangeWatchedFilesClientCapabilities   `json:"didChangeWatchedFiles,omitempty"`
+	DidChangeConfiguration *DidChangeConfigurationClientCapabilities `json:"didChangeConfiguration,omitempty"`
+	DidChangeWatchedFiles  *DidChangeWatchedFilesClientCapabilities  `json:"didChangeWatchedFiles,omitempty"`
 	// Symbol                 *WorkspaceSymbolClientCapabilities         `json:"symbol,omitempty"`
 	// ExecuteCommand         *ExecuteCommandClientCapabilities          `json:"executeCommand,omitempty"`
 	WorkspaceFolders bool `json:"workspaceFolders,omitempty"`
@@ -38,8 +206,8 @@ type WorkspaceOptions struct {
 }
 
 type WorkspaceFoldersServerCapabilities struct {
-	Supported           bool   `json:"supported,omitempty"`
-	ChangeNotifications string `json:"changeNotifications,omitempty"`
+	Supported           bool                   `json:"supported,omitempty"`
+	ChangeNotifications *Choice2[string, bool] `json:"changeNotifications,omitempty"`
 }
 
 type WorkspaceFileOperationsServerCapabilities struct {